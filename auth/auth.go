@@ -0,0 +1,150 @@
+// Package auth authenticates the callers of the services with bearer
+// tokens, JWTs signed with the keys of a local JWKS file or static API
+// keys, and keeps the authenticated principal in the context
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// Config tells how the tokens are checked, see the config package
+type Config struct {
+	Enabled     bool   `config:"enabled" usage:"Require a bearer token on every RPC except the health checks and reflection"`
+	JWKSFile    string `config:"jwks-file" usage:"JSON Web Key Set file with the HMAC and RSA keys of the JWTs"`
+	Issuer      string `config:"issuer" usage:"Required iss claim of the JWTs, not checked if empty"`
+	Audience    string `config:"audience" usage:"Required aud claim of the JWTs, not checked if empty"`
+	APIKeysFile string `config:"api-keys-file" usage:"JSON file with the static API keys"`
+	PolicyFile  string `config:"policy-file" usage:"YAML file with the per method authorization rules, see the authz package. Everything is allowed if empty"`
+}
+
+// Validate checks there are keys when the authentication is enabled
+func (c *Config) Validate() error {
+	if c.Enabled && c.JWKSFile == "" && c.APIKeysFile == "" {
+		return errors.New("auth.jwks-file or auth.api-keys-file is required with auth")
+	}
+
+	return nil
+}
+
+// Principal is the authenticated caller
+type Principal struct {
+	// Subject is the sub claim of a JWT or the name of an API key
+	Subject string
+	Roles   []string
+	// Method is how the caller was authenticated: jwt or api-key
+	Method string
+}
+
+// HasRole tells if the principal has the role
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+type principalKey struct{}
+
+// NewContext returns a context with the principal
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal of the RPC. ok is false when the
+// server does not authenticate the callers
+func FromContext(ctx context.Context) (p *Principal, ok bool) {
+	p, ok = ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// apiKey is a static API key of the api-keys-file:
+//
+//	{"keys": [{"name": "blog-importer", "key": "...", "roles": ["editor"]}]}
+type apiKey struct {
+	Name  string   `json:"name"`
+	Key   string   `json:"key"`
+	Roles []string `json:"roles"`
+}
+
+// Authenticator checks the bearer tokens
+type Authenticator struct {
+	keys     *keySet
+	issuer   string
+	audience string
+	apiKeys  []apiKey
+	// anonymous tells the methods which can be called without a token
+	anonymous func(fullMethod string) bool
+}
+
+// New loads the JWKS and the API keys files
+func New(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{issuer: cfg.Issuer, audience: cfg.Audience}
+	if cfg.JWKSFile != "" {
+		keys, err := loadKeySet(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the JWKS: %v", err)
+		}
+		a.keys = keys
+	}
+	if cfg.APIKeysFile != "" {
+		b, err := ioutil.ReadFile(cfg.APIKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the API keys: %v", err)
+		}
+		var file struct {
+			Keys []apiKey `json:"keys"`
+		}
+		if err := json.Unmarshal(b, &file); err != nil {
+			return nil, fmt.Errorf("cannot load the API keys: %v", err)
+		}
+		for _, key := range file.Keys {
+			if key.Name == "" || key.Key == "" {
+				return nil, errors.New("cannot load the API keys: every key needs a name and a key")
+			}
+		}
+		a.apiKeys = file.Keys
+	}
+
+	return a, nil
+}
+
+// AllowAnonymous lets the callers without a token call the methods fn
+// returns true for. They have no principal in the context
+func (a *Authenticator) AllowAnonymous(fn func(fullMethod string) bool) {
+	a.anonymous = fn
+}
+
+// Authenticate returns the principal of the token. Tokens with three dot
+// separated parts are JWTs, the others API keys
+func (a *Authenticator) Authenticate(token string) (*Principal, error) {
+	if strings.Count(token, ".") == 2 {
+		if a.keys == nil {
+			return nil, errors.New("JWTs are not accepted")
+		}
+		claims, err := a.keys.verify(token)
+		if err != nil {
+			return nil, err
+		}
+		if err := claims.check(a.issuer, a.audience); err != nil {
+			return nil, err
+		}
+		return &Principal{Subject: claims.Subject, Roles: claims.Roles, Method: "jwt"}, nil
+	}
+
+	for _, key := range a.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key.Key), []byte(token)) == 1 {
+			return &Principal{Subject: key.Name, Roles: key.Roles, Method: "api-key"}, nil
+		}
+	}
+
+	return nil, errors.New("unknown API key")
+}
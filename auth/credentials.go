@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"context"
+)
+
+// TokenCredentials sends the bearer token with every RPC of a client
+// connection, see grpc.WithPerRPCCredentials
+type TokenCredentials struct {
+	token      string
+	requireTLS bool
+}
+
+// NewTokenCredentials returns the credentials of the token. Without
+// requireTLS the token is also sent over plaintext connections
+func NewTokenCredentials(token string, requireTLS bool) *TokenCredentials {
+	return &TokenCredentials{token: token, requireTLS: requireTLS}
+}
+
+// GetRequestMetadata returns the authorization metadata
+func (c *TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+// RequireTransportSecurity tells if the token can be sent only over TLS
+func (c *TokenCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
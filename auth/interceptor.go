@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// publicServices are the services called without a token: the
+// container probes and the reflection used by the cli tools
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// IsPublic tells if the method can be called without a token
+func IsPublic(fullMethod string) bool {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authenticate returns the context with the principal of the
+// authorization metadata
+func (a *Authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		if a.anonymous != nil && a.anonymous(fullMethod) {
+			return ctx, nil
+		}
+		return nil, rpcerror.New(codes.Unauthenticated, "TOKEN_MISSING", "Authorization bearer token is required").Err()
+	}
+
+	const prefix = "bearer "
+	if len(values[0]) <= len(prefix) || !strings.EqualFold(values[0][:len(prefix)], prefix) {
+		return nil, rpcerror.New(codes.Unauthenticated, "TOKEN_INVALID", "Authorization must be a bearer token").Err()
+	}
+	p, err := a.Authenticate(values[0][len(prefix):])
+	if err != nil {
+		return nil, rpcerror.New(codes.Unauthenticated, "TOKEN_INVALID", "Invalid token: "+err.Error()).Err()
+	}
+
+	return NewContext(ctx, p), nil
+}
+
+// UnaryServerInterceptor rejects the requests without a valid token and
+// passes the principal to the handler in the context
+func UnaryServerInterceptor(a *Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if IsPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects the streams without a valid token and
+// passes the principal to the handler in the stream context
+func StreamServerInterceptor(a *Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if IsPublic(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	a := newTestAuthenticator(t)
+	a.AllowAnonymous(func(fullMethod string) bool {
+		return fullMethod == "/calculator.CalculatorService/Sum"
+	})
+	interceptor := UnaryServerInterceptor(a)
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		code          codes.Code
+		reason        string
+		// subject of the principal the handler gets, no principal if empty
+		subject string
+	}{
+		{"without token", "/blog.BlogService/ReadBlog", "", codes.Unauthenticated, "TOKEN_MISSING", ""},
+		{"not bearer", "/blog.BlogService/ReadBlog", "Basic secret-key", codes.Unauthenticated, "TOKEN_INVALID", ""},
+		{"empty bearer", "/blog.BlogService/ReadBlog", "Bearer ", codes.Unauthenticated, "TOKEN_INVALID", ""},
+		{"unknown API key", "/blog.BlogService/ReadBlog", "Bearer wrong", codes.Unauthenticated, "TOKEN_INVALID", ""},
+		{"API key", "/blog.BlogService/ReadBlog", "Bearer secret-key", codes.OK, "", "importer"},
+		{"lower case bearer", "/blog.BlogService/ReadBlog", "bearer secret-key", codes.OK, "", "importer"},
+		{"health without token", "/grpc.health.v1.Health/Check", "", codes.OK, "", ""},
+		{"reflection without token", "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", "", codes.OK, "", ""},
+		{"anonymous method", "/calculator.CalculatorService/Sum", "", codes.OK, "", ""},
+		{"anonymous method with invalid token", "/calculator.CalculatorService/Sum", "Bearer wrong", codes.Unauthenticated, "TOKEN_INVALID", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+
+			var principal *Principal
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				principal, _ = FromContext(ctx)
+				return "ok", nil
+			}
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("code = %v, want %v (%v)", code, tt.code, err)
+			}
+			if tt.reason != "" {
+				details, _ := rpcerror.FromError(err)
+				if details.Reason() != tt.reason {
+					t.Errorf("reason = %q, want %q", details.Reason(), tt.reason)
+				}
+			}
+			if tt.subject == "" && principal != nil {
+				t.Errorf("handler got principal %+v, want none", principal)
+			}
+			if tt.subject != "" && (principal == nil || principal.Subject != tt.subject) {
+				t.Errorf("handler got principal %+v, want %s", principal, tt.subject)
+			}
+		})
+	}
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor(newTestAuthenticator(t))
+	info := &grpc.StreamServerInfo{FullMethod: "/blog.BlogService/ListBlog"}
+
+	var principal *Principal
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		principal, _ = FromContext(ss.Context())
+		return nil
+	}
+
+	err := interceptor(nil, &testStream{ctx: context.Background()}, info, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("stream without token: code = %v, want Unauthenticated", status.Code(err))
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer secret-key"))
+	if err := interceptor(nil, &testStream{ctx: ctx}, info, handler); err != nil {
+		t.Fatalf("stream with API key: %v", err)
+	}
+	if principal == nil || principal.Subject != "importer" {
+		t.Errorf("handler got principal %+v, want importer", principal)
+	}
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // the hashes of the HS and RS algorithms
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// leeway is the clock difference allowed for exp and nbf
+const leeway = time.Minute
+
+// algorithms are the accepted JWT algorithms by the key type they need
+var algorithms = map[string]struct {
+	kty  string
+	hash crypto.Hash
+}{
+	"HS256": {"oct", crypto.SHA256},
+	"HS384": {"oct", crypto.SHA384},
+	"HS512": {"oct", crypto.SHA512},
+	"RS256": {"RSA", crypto.SHA256},
+	"RS384": {"RSA", crypto.SHA384},
+	"RS512": {"RSA", crypto.SHA512},
+}
+
+// jwk is a JSON Web Key, only the oct and RSA key types are used
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	// K is the HMAC secret of oct keys
+	K string `json:"k"`
+	// N and E are the RSA public key
+	N string `json:"n"`
+	E string `json:"e"`
+
+	secret []byte
+	public *rsa.PublicKey
+}
+
+type keySet struct {
+	Keys []*jwk `json:"keys"`
+}
+
+// loadKeySet reads a JWKS file. Keys of the other types are skipped
+func loadKeySet(file string) (*keySet, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	set := &keySet{}
+	if err := json.Unmarshal(b, set); err != nil {
+		return nil, err
+	}
+
+	keys := set.Keys[:0]
+	for _, key := range set.Keys {
+		switch key.Kty {
+		case "oct":
+			key.secret, err = base64.RawURLEncoding.DecodeString(key.K)
+			if err != nil || len(key.secret) == 0 {
+				return nil, fmt.Errorf("invalid k of the key %q", key.Kid)
+			}
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(key.N)
+			e, errE := base64.RawURLEncoding.DecodeString(key.E)
+			if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+				return nil, fmt.Errorf("invalid n or e of the key %q", key.Kid)
+			}
+			key.public = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		default:
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no oct or RSA keys")
+	}
+	set.Keys = keys
+
+	return set, nil
+}
+
+// claims are the JWT claims used for the principal
+type claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Roles     []string `json:"roles"`
+}
+
+// audience is the aud claim, a string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+
+	return json.Unmarshal(b, (*[]string)(a))
+}
+
+// verify checks the signature of the token with the keys of the set and
+// returns its claims
+func (s *keySet) verify(token string) (*claims, error) {
+	parts := strings.Split(token, ".")
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodePart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid JWT header: %v", err)
+	}
+	alg, ok := algorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("invalid JWT signature")
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range s.Keys {
+		// The key type must fit the algorithm so an RSA public key is
+		// never used as an HMAC secret
+		if key.Kty != alg.kty || (header.Kid != "" && key.Kid != header.Kid) || (key.Alg != "" && key.Alg != header.Alg) {
+			continue
+		}
+		if key.check(alg.hash, signed, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("invalid JWT signature")
+	}
+
+	c := &claims{}
+	if err := decodePart(parts[1], c); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %v", err)
+	}
+
+	return c, nil
+}
+
+// check verifies the signature with the key
+func (k *jwk) check(hash crypto.Hash, signed, signature []byte) bool {
+	if k.secret != nil {
+		mac := hmac.New(hash.New, k.secret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	return rsa.VerifyPKCS1v15(k.public, hash, h.Sum(nil), signature) == nil
+}
+
+// check validates the times, the issuer and the audience
+func (c *claims) check(issuer, aud string) error {
+	now := time.Now()
+	if c.Subject == "" {
+		return errors.New("JWT without sub")
+	}
+	if c.ExpiresAt == nil {
+		return errors.New("JWT without exp")
+	}
+	if now.After(time.Unix(*c.ExpiresAt, 0).Add(leeway)) {
+		return errors.New("JWT is expired")
+	}
+	if c.NotBefore != nil && now.Add(leeway).Before(time.Unix(*c.NotBefore, 0)) {
+		return errors.New("JWT is not valid yet")
+	}
+	if issuer != "" && c.Issuer != issuer {
+		return fmt.Errorf("JWT issuer %q is not accepted", c.Issuer)
+	}
+	if aud != "" && !containsString(c.Audience, aud) {
+		return fmt.Errorf("JWT is not for the audience %q", aud)
+	}
+
+	return nil
+}
+
+func decodePart(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
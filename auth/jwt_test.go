@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testSecret = []byte("0123456789abcdef0123456789abcdef")
+	testRSAKey *rsa.PrivateKey
+)
+
+func init() {
+	var err error
+	testRSAKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func encodePart(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// signHS signs the token with the HMAC secret
+func signHS(t *testing.T, header, claims map[string]interface{}, secret []byte) string {
+	signed := encodePart(t, header) + "." + encodePart(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signRS signs the token with the test RSA key
+func signRS(t *testing.T, header, claims map[string]interface{}) string {
+	signed := encodePart(t, header) + "." + encodePart(t, claims)
+	hash := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, testRSAKey, crypto.SHA256, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// newTestAuthenticator writes the JWKS with the HMAC key h1 and the RSA
+// key r1 and the API key of the importer
+func newTestAuthenticator(t *testing.T) *Authenticator {
+	dir, err := ioutil.TempDir("", "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "oct", "kid": "h1", "alg": "HS256", "k": b64(testSecret)},
+		{"kty": "RSA", "kid": "r1", "n": b64(testRSAKey.N.Bytes()), "e": b64(big.NewInt(int64(testRSAKey.E)).Bytes())},
+		{"kty": "EC", "kid": "skipped"},
+	}}
+	b, _ := json.Marshal(jwks)
+	jwksFile := filepath.Join(dir, "jwks.json")
+	if err := ioutil.WriteFile(jwksFile, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	keysFile := filepath.Join(dir, "api_keys.json")
+	if err := ioutil.WriteFile(keysFile, []byte(`{"keys": [{"name": "importer", "key": "secret-key", "roles": ["editor"]}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := New(Config{Enabled: true, JWKSFile: jwksFile, APIKeysFile: keysFile, Issuer: "dev", Audience: "grpc-go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "alice",
+		"iss":   "dev",
+		"aud":   []string{"other", "grpc-go"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"admin"},
+	}
+}
+
+func claimsWith(key string, value interface{}) map[string]interface{} {
+	claims := validClaims()
+	if value == nil {
+		delete(claims, key)
+	} else {
+		claims[key] = value
+	}
+
+	return claims
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	a := newTestAuthenticator(t)
+	hs := map[string]interface{}{"alg": "HS256", "kid": "h1"}
+	rs := map[string]interface{}{"alg": "RS256", "kid": "r1"}
+	// The RSA public key used as an HMAC secret
+	publicAsSecret := testRSAKey.N.Bytes()
+
+	tests := []struct {
+		name  string
+		token string
+		// err is a part of the expected error, no error if empty
+		err string
+	}{
+		{"HS256", signHS(t, hs, validClaims(), testSecret), ""},
+		{"RS256", signRS(t, rs, validClaims()), ""},
+		{"HS256 without kid", signHS(t, map[string]interface{}{"alg": "HS256"}, validClaims(), testSecret), ""},
+		{"audience string", signHS(t, hs, claimsWith("aud", "grpc-go"), testSecret), ""},
+		{"alg none", encodePart(t, map[string]string{"alg": "none"}) + "." + encodePart(t, validClaims()) + ".", "unsupported JWT algorithm"},
+		{"alg not supported", signHS(t, map[string]interface{}{"alg": "ES256"}, validClaims(), testSecret), "unsupported JWT algorithm"},
+		{"HS256 with the RSA key", signHS(t, map[string]interface{}{"alg": "HS256", "kid": "r1"}, validClaims(), publicAsSecret), "invalid JWT signature"},
+		{"RS256 with the HMAC key", signRS(t, map[string]interface{}{"alg": "RS256", "kid": "h1"}, validClaims()), "invalid JWT signature"},
+		{"HS384 with a HS256 key", signHS(t, map[string]interface{}{"alg": "HS384", "kid": "h1"}, validClaims(), testSecret), "invalid JWT signature"},
+		{"unknown kid", signHS(t, map[string]interface{}{"alg": "HS256", "kid": "h2"}, validClaims(), testSecret), "invalid JWT signature"},
+		{"forged signature", signHS(t, hs, validClaims(), []byte("another secret")), "invalid JWT signature"},
+		{"malformed header", "not-base64!." + encodePart(t, validClaims()) + ".sig", "invalid JWT header"},
+		{"expired", signHS(t, hs, claimsWith("exp", time.Now().Add(-time.Hour).Unix()), testSecret), "expired"},
+		{"expired within leeway", signHS(t, hs, claimsWith("exp", time.Now().Add(-leeway/2).Unix()), testSecret), ""},
+		{"without exp", signHS(t, hs, claimsWith("exp", nil), testSecret), "without exp"},
+		{"not valid yet", signHS(t, hs, claimsWith("nbf", time.Now().Add(time.Hour).Unix()), testSecret), "not valid yet"},
+		{"wrong issuer", signHS(t, hs, claimsWith("iss", "prod"), testSecret), "issuer"},
+		{"wrong audience", signHS(t, hs, claimsWith("aud", "other"), testSecret), "audience"},
+		{"without sub", signHS(t, hs, claimsWith("sub", nil), testSecret), "without sub"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.Authenticate(tt.token)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("Authenticate() error = %v", err)
+				}
+				if p.Subject != "alice" || p.Method != "jwt" || !p.HasRole("admin") {
+					t.Errorf("Authenticate() = %+v, want alice with the admin role", p)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Authenticate() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	a := newTestAuthenticator(t)
+
+	p, err := a.Authenticate("secret-key")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if p.Subject != "importer" || p.Method != "api-key" || !p.HasRole("editor") {
+		t.Errorf("Authenticate() = %+v, want the importer API key", p)
+	}
+
+	for _, token := range []string{"", "secret", "secret-key-2"} {
+		if _, err := a.Authenticate(token); err == nil {
+			t.Errorf("Authenticate(%q) accepted an unknown API key", token)
+		}
+	}
+}
+
+func TestAuthenticateJWTWithoutJWKS(t *testing.T) {
+	a, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := signHS(t, map[string]interface{}{"alg": "HS256"}, validClaims(), testSecret)
+	if _, err := a.Authenticate(token); err == nil {
+		t.Error("Authenticate() accepted a JWT without a JWKS")
+	}
+}
@@ -0,0 +1,211 @@
+// Package authz allows or denies the RPCs by the rules of a policy file
+// matching the method, the roles of the principal and the owner of the
+// resource the request is about
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/KestutisKazlauskas/grpc-go/auth"
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"gopkg.in/yaml.v2"
+)
+
+// Rule allows or denies the methods to the principals it matches
+type Rule struct {
+	// Methods are full method names (/blog.BlogService/UpdateBlog), all the
+	// methods of a service (/blog.BlogService/*) or every method (*)
+	Methods []string `yaml:"methods"`
+	// Everyone matches the callers without a principal too
+	Everyone bool `yaml:"everyone"`
+	// Roles matches the principals with any of the roles, any principal
+	// if empty
+	Roles []string `yaml:"roles"`
+	// Owner matches only the owner of the resource of the request
+	Owner bool `yaml:"owner"`
+	// Effect is allow or deny
+	Effect string `yaml:"effect"`
+}
+
+func (r *Rule) validate() error {
+	if len(r.Methods) == 0 {
+		return errors.New("methods can not be empty")
+	}
+	if r.Effect != "allow" && r.Effect != "deny" {
+		return fmt.Errorf("unknown effect %q, use allow or deny", r.Effect)
+	}
+	if r.Everyone && (len(r.Roles) > 0 || r.Owner) {
+		return errors.New("everyone can not be used with roles or owner")
+	}
+
+	return nil
+}
+
+func (r *Rule) matchesMethod(fullMethod string) bool {
+	for _, method := range r.Methods {
+		if method == "*" || method == fullMethod {
+			return true
+		}
+		if strings.HasSuffix(method, "/*") && strings.HasPrefix(fullMethod, strings.TrimSuffix(method, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OwnerFunc returns the owner of the resource the request is about,
+// empty if the resource does not exist or the request gives it to
+// another owner
+type OwnerFunc func(ctx context.Context, req interface{}) (string, error)
+
+// Policy is the ordered rules of a policy file. The first rule matching an
+// RPC decides, the RPCs matched by no rule are denied
+type Policy struct {
+	Rules []*Rule `yaml:"rules"`
+
+	mu     sync.RWMutex
+	owners map[string]OwnerFunc
+}
+
+// Load reads the YAML policy file
+func Load(file string) (*Policy, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{}
+	if err := yaml.UnmarshalStrict(b, p); err != nil {
+		return nil, err
+	}
+	for i, rule := range p.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %v", i+1, err)
+		}
+	}
+
+	return p, nil
+}
+
+// SetOwners adds how the owners of the requests of the methods are found.
+// Does nothing on a nil policy so it can be called without a policy file
+func (p *Policy) SetOwners(owners map[string]OwnerFunc) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.owners == nil {
+		p.owners = map[string]OwnerFunc{}
+	}
+	for method, fn := range owners {
+		p.owners[method] = fn
+	}
+}
+
+// matches tells if the rule applies to the caller. req is nil for the
+// streams so the owner rules never match them
+func (p *Policy) matches(ctx context.Context, rule *Rule, fullMethod string, req interface{}) (bool, error) {
+	if rule.Everyone {
+		return true, nil
+	}
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return false, nil
+	}
+	if len(rule.Roles) > 0 && !hasAnyRole(principal, rule.Roles) {
+		return false, nil
+	}
+	if !rule.Owner {
+		return true, nil
+	}
+
+	p.mu.RLock()
+	ownerOf, ok := p.owners[fullMethod]
+	p.mu.RUnlock()
+	if !ok || req == nil {
+		return false, nil
+	}
+	owner, err := ownerOf(ctx, req)
+	if err != nil {
+		return false, err
+	}
+
+	return owner != "" && owner == principal.Subject, nil
+}
+
+// Authorize returns a PermissionDenied error if the policy does not allow
+// the RPC
+func (p *Policy) Authorize(ctx context.Context, fullMethod string, req interface{}) error {
+	if auth.IsPublic(fullMethod) {
+		return nil
+	}
+
+	for _, rule := range p.Rules {
+		if !rule.matchesMethod(fullMethod) {
+			continue
+		}
+		ok, err := p.matches(ctx, rule, fullMethod, req)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if rule.Effect == "allow" {
+			return nil
+		}
+		break
+	}
+
+	return rpcerror.New(codes.PermissionDenied, "PERMISSION_DENIED", "Not allowed to call "+fullMethod).
+		WithMetadata("method", fullMethod).Err()
+}
+
+// AllowsAnonymous tells if the policy allows the method to the callers
+// without a principal
+func (p *Policy) AllowsAnonymous(fullMethod string) bool {
+	return p.Authorize(context.Background(), fullMethod, nil) == nil
+}
+
+func hasAnyRole(p *auth.Principal, roles []string) bool {
+	for _, role := range roles {
+		if p.HasRole(role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnaryServerInterceptor rejects the requests the policy does not allow
+func UnaryServerInterceptor(p *Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := p.Authorize(ctx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects the streams the policy does not allow.
+// The requests are not known yet so the owner rules do not match streams
+func StreamServerInterceptor(p *Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := p.Authorize(ss.Context(), info.FullMethod, nil); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,193 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/auth"
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testPolicy = `
+rules:
+  - methods: ["/calculator.CalculatorService/*"]
+    everyone: true
+    effect: allow
+  - methods: ["*"]
+    roles: [admin]
+    effect: allow
+  - methods: &owned
+      - /blog.BlogService/UpdateBlog
+    owner: true
+    effect: allow
+  - methods: *owned
+    effect: deny
+  - methods: ["/blog.BlogService/ImportBlogs"]
+    effect: deny
+  - methods: ["/blog.BlogService/*"]
+    effect: allow
+`
+
+// testRequest is a request of the blog with the owner
+type testRequest struct {
+	owner string
+}
+
+// writePolicy writes the policy to a file removed after the test
+func writePolicy(t *testing.T, policy string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "authz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	file := filepath.Join(dir, "policy.yaml")
+	if err := ioutil.WriteFile(file, []byte(policy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func loadTestPolicy(t *testing.T, policy string) *Policy {
+	t.Helper()
+	p, err := Load(writePolicy(t, policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetOwners(map[string]OwnerFunc{
+		"/blog.BlogService/UpdateBlog": func(ctx context.Context, req interface{}) (string, error) {
+			return req.(*testRequest).owner, nil
+		},
+	})
+
+	return p
+}
+
+func as(subject string, roles ...string) context.Context {
+	return auth.NewContext(context.Background(), &auth.Principal{Subject: subject, Roles: roles, Method: "jwt"})
+}
+
+func TestAuthorize(t *testing.T) {
+	p := loadTestPolicy(t, testPolicy)
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		req    interface{}
+		allow  bool
+	}{
+		{"everyone anonymous", context.Background(), "/calculator.CalculatorService/Sum", nil, true},
+		{"everyone authenticated", as("alice"), "/calculator.CalculatorService/Sum", nil, true},
+		{"anonymous on authenticated method", context.Background(), "/blog.BlogService/ReadBlog", nil, false},
+		{"authenticated method", as("alice"), "/blog.BlogService/ReadBlog", nil, true},
+		{"owner", as("alice"), "/blog.BlogService/UpdateBlog", &testRequest{"alice"}, true},
+		{"not owner", as("bob"), "/blog.BlogService/UpdateBlog", &testRequest{"alice"}, false},
+		{"no owner", as("alice"), "/blog.BlogService/UpdateBlog", &testRequest{""}, false},
+		{"owner rule on stream", as("alice"), "/blog.BlogService/UpdateBlog", nil, false},
+		{"admin before owner rule", as("bob", "admin"), "/blog.BlogService/UpdateBlog", &testRequest{"alice"}, true},
+		{"admin before deny", as("bob", "admin"), "/blog.BlogService/ImportBlogs", nil, true},
+		{"deny before catch all", as("alice"), "/blog.BlogService/ImportBlogs", nil, false},
+		{"default deny", as("alice"), "/blog.CommentService/DeleteComment", nil, false},
+		{"default deny anonymous", context.Background(), "/greet.GreetService/Greet", nil, false},
+		{"health is public", context.Background(), "/grpc.health.v1.Health/Check", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Authorize(tt.ctx, tt.method, tt.req)
+			if tt.allow && err != nil {
+				t.Fatalf("Authorize() = %v, want allowed", err)
+			}
+			if !tt.allow {
+				if code := status.Code(err); code != codes.PermissionDenied {
+					t.Fatalf("code = %v, want PermissionDenied (%v)", code, err)
+				}
+				details, _ := rpcerror.FromError(err)
+				if details.Reason() != "PERMISSION_DENIED" {
+					t.Errorf("reason = %q, want PERMISSION_DENIED", details.Reason())
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizeOwnerError(t *testing.T) {
+	p := loadTestPolicy(t, testPolicy)
+	ownerErr := errors.New("store down")
+	p.SetOwners(map[string]OwnerFunc{
+		"/blog.BlogService/UpdateBlog": func(ctx context.Context, req interface{}) (string, error) {
+			return "", ownerErr
+		},
+	})
+
+	if err := p.Authorize(as("alice"), "/blog.BlogService/UpdateBlog", &testRequest{}); err != ownerErr {
+		t.Errorf("Authorize() = %v, want %v", err, ownerErr)
+	}
+}
+
+func TestAllowsAnonymous(t *testing.T) {
+	p := loadTestPolicy(t, testPolicy)
+
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"/calculator.CalculatorService/Sum", true},
+		{"/blog.BlogService/ReadBlog", false},
+		{"/blog.BlogService/UpdateBlog", false},
+	}
+
+	for _, tt := range tests {
+		if got := p.AllowsAnonymous(tt.method); got != tt.want {
+			t.Errorf("AllowsAnonymous(%s) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+	}{
+		{"unknown key", "rules:\n  - methods: [\"*\"]\n    effect: allow\n    role: admin\n"},
+		{"unknown effect", "rules:\n  - methods: [\"*\"]\n    effect: permit\n"},
+		{"no methods", "rules:\n  - effect: allow\n"},
+		{"everyone with roles", "rules:\n  - methods: [\"*\"]\n    everyone: true\n    roles: [admin]\n    effect: allow\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(writePolicy(t, tt.policy)); err == nil {
+				t.Error("Load() = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(loadTestPolicy(t, testPolicy))
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return "ok", nil
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/blog.BlogService/UpdateBlog"}
+	if _, err := interceptor(as("bob"), &testRequest{"alice"}, info, handler); status.Code(err) != codes.PermissionDenied || called {
+		t.Fatalf("not owner: err = %v, called = %v, want PermissionDenied without the handler", err, called)
+	}
+	if _, err := interceptor(as("alice"), &testRequest{"alice"}, info, handler); err != nil || !called {
+		t.Fatalf("owner: err = %v, called = %v, want the handler", err, called)
+	}
+}
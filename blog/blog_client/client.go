@@ -5,18 +5,27 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
+	"time"
 
 	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
-	"google.golang.org/grpc"
+	"github.com/KestutisKazlauskas/grpc-go/config"
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func main() {
 
 	fmt.Println("Client started")
 
-	//connection without ssl
-	opts := grpc.WithInsecure() //if want to use without tsl
-	conn, err := grpc.Dial("localhost:50051", opts)
+	cfg := &config.Client{Target: "localhost:50051"}
+	if err := config.Load("BLOG_CLIENT", cfg, os.Args[1:]); err != nil {
+		log.Fatalf("Config error %v", err)
+	}
+
+	//connection without ssl unless tls.enabled
+	conn, err := cfg.Dial()
 	if err != nil {
 		log.Fatalf("Error on initiatning connection %v", err)
 	}
@@ -24,9 +33,25 @@ func main() {
 
 	c := blogpb.NewBlogServiceClient(conn)
 
+	//Print all the changes made below
+	go watchBlogs(c)
+
+	authorID, betterAuthorID := doAuthors(blogpb.NewAuthorServiceClient(conn))
+
+	//Blogs of unknown authors are rejected with FAILED_PRECONDITION
+	unknownAuthorBlog := &blogpb.Blog{AuthorId: "AuthorString", Title: "Nobody wrote it"}
+	if _, err := c.CreateBlog(context.Background(), &blogpb.CreateBlogRequest{Blog: unknownAuthorBlog}); err != nil {
+		fmt.Printf("Blog of unknown author rejected: %v\n", errorDetails(err))
+	}
+
+	//Invalid blogs are rejected with INVALID_ARGUMENT and the field violations
+	if _, err := c.CreateBlog(context.Background(), &blogpb.CreateBlogRequest{Blog: &blogpb.Blog{AuthorId: authorID}}); err != nil {
+		fmt.Printf("Invalid blog rejected: %v\n", errorDetails(err))
+	}
+
 	fmt.Println("Creating a blog.")
 	blog := &blogpb.Blog{
-		AuthorId: "AuthorString",
+		AuthorId: authorID,
 		Title:    "My first blog in Go",
 		Content:  "A lot of good struf",
 	}
@@ -37,13 +62,20 @@ func main() {
 	}
 	fmt.Printf("Blog has been created with a response: %v", createBlogRes)
 
+	//New blogs are drafts, they are not listed or searched until published
+	publishRes, err := c.PublishBlog(context.Background(), &blogpb.PublishBlogRequest{BlogId: createBlogRes.GetBlog().GetId()})
+	if err != nil {
+		log.Fatalf("Error on publishing blog: %v", err)
+	}
+	fmt.Printf("Blog published: %v %v\n", publishRes.GetBlog().GetStatus(), publishRes.GetBlog().GetPublishTime())
+
 	//read blog
 	fmt.Println("Reading the blog")
 
 	_, readErr := c.ReadBlog(context.Background(), &blogpb.ReadBlogRequest{BlogId: "5ecd425c6a09822c9aa1781e"})
 
 	if readErr != nil {
-		fmt.Printf("Error while reading: %v\n", readErr)
+		fmt.Printf("Error while reading: %v\n", errorDetails(readErr))
 	}
 
 	blogID := createBlogRes.GetBlog().GetId()
@@ -56,15 +88,29 @@ func main() {
 
 	fmt.Printf("Read blog response: %v\n", readRes)
 
+	//Search blogs
+	searchRes, searchErr := c.SearchBlogs(context.Background(), &blogpb.SearchBlogsRequest{Query: "blog go"})
+	if searchErr != nil {
+		fmt.Printf("Error while searching: %v\n", searchErr)
+	}
+
+	for _, result := range searchRes.GetResults() {
+		fmt.Printf("Search result %v score %v snippets %v\n", result.GetBlog().GetId(), result.GetScore(), result.GetSnippets())
+	}
+
 	//Update the blog
 	updatedBlog := &blogpb.Blog{
 		Id:       blogID,
-		AuthorId: "AuthorString the better",
+		AuthorId: betterAuthorID,
 		Title:    "My first blog in Go this change too",
 		Content:  "A lot of good struf.Last but not least",
 	}
 
-	updateBlogReq := &blogpb.UpdateBlogRequest{Blog: updatedBlog}
+	//Fails with ABORTED if somebody changed the blog after we read it
+	updateBlogReq := &blogpb.UpdateBlogRequest{
+		Blog:             updatedBlog,
+		ExpectedRevision: readRes.GetBlog().GetRevision(),
+	}
 	updateRes, updateErr := c.UpdateBlog(context.Background(), updateBlogReq)
 	if updateErr != nil {
 		fmt.Printf("Error on update blog: %v\n", updateErr)
@@ -72,6 +118,38 @@ func main() {
 
 	fmt.Printf("Blog updated: %v\n", updateRes)
 
+	//Update only the title
+	titleUpdateReq := &blogpb.UpdateBlogRequest{
+		Blog:       &blogpb.Blog{Id: blogID, Title: "Only the title is changed"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+	}
+	titleUpdateRes, updateErr := c.UpdateBlog(context.Background(), titleUpdateReq)
+	if updateErr != nil {
+		fmt.Printf("Error on update blog title: %v\n", updateErr)
+	}
+
+	fmt.Printf("Blog title updated: %v\n", titleUpdateRes)
+
+	//Update with an old revision
+	staleUpdateReq := &blogpb.UpdateBlogRequest{
+		Blog:             updatedBlog,
+		ExpectedRevision: readRes.GetBlog().GetRevision(),
+	}
+	if _, updateErr := c.UpdateBlog(context.Background(), staleUpdateReq); updateErr != nil {
+		details, _ := rpcerror.FromError(updateErr)
+		if details.Reason() == "REVISION_MISMATCH" {
+			fmt.Println("Stale update rejected, the blog must be read again")
+		} else {
+			fmt.Printf("Error on stale update: %v\n", details)
+		}
+	}
+
+	doRevisions(c, blogID)
+
+	doComments(blogpb.NewCommentServiceClient(conn), blogID)
+
+	doSchedule(c, blogID)
+
 	//Delete blog
 	deleteBlogReq := &blogpb.DeleteBlogRequest{BlogId: blogID}
 	deleteRes, deleteErr := c.DeleteBlog(context.Background(), deleteBlogReq)
@@ -81,23 +159,351 @@ func main() {
 
 	fmt.Printf("Delete success %v\n", deleteRes)
 
-	//List all the blogs
-	stream, err := c.ListBlog(context.Background(), &blogpb.ListBlogRequest{})
+	//Restore the soft deleted blog
+	undeleteRes, undeleteErr := c.UndeleteBlog(context.Background(), &blogpb.UndeleteBlogRequest{BlogId: blogID})
+	if undeleteErr != nil {
+		log.Fatalf("Undelete err %v\n", undeleteErr)
+	}
+
+	fmt.Printf("Undelete success %v\n", undeleteRes)
+
+	//Delete permanently
+	purgeBlogReq := &blogpb.DeleteBlogRequest{BlogId: blogID, Purge: true}
+	if _, deleteErr := c.DeleteBlog(context.Background(), purgeBlogReq); deleteErr != nil {
+		log.Fatalf("Purge err %v\n", deleteErr)
+	}
+
+	fmt.Printf("Purge success %v\n", blogID)
+
+	doBatch(c, authorID)
+
+	//List all the blogs page by page
+	listBlogReq := &blogpb.ListBlogRequest{PageSize: 10}
+	for {
+		stream, err := c.ListBlog(context.Background(), listBlogReq)
+		if err != nil {
+			log.Fatalf("Error on streaming %v", err)
+		}
+
+		nextPageToken := ""
+		for {
+			blog, err := stream.Recv()
+			if err == io.EOF {
+				//Finished streaming
+				break
+			}
+
+			if err != nil {
+				log.Fatalf("Error while streaming blog %v\n", err)
+			}
+
+			fmt.Printf("Blog list item streamed: %v \n", blog.GetBlog())
+			nextPageToken = blog.GetNextPageToken()
+		}
+
+		if nextPageToken == "" {
+			break
+		}
+		listBlogReq.PageToken = nextPageToken
+	}
+}
+
+func doRevisions(c blogpb.BlogServiceClient, blogID string) {
+	stream, err := c.ListBlogRevisions(context.Background(), &blogpb.ListBlogRevisionsRequest{BlogId: blogID})
 	if err != nil {
-		log.Fatalf("Error on streaming %v", err)
+		log.Fatalf("Error on listing revisions %v", err)
 	}
 
 	for {
-		blog, err := stream.Recv()
+		res, err := stream.Recv()
 		if err == io.EOF {
-			//Finished streaming
 			break
 		}
+		if err != nil {
+			log.Fatalf("Error while streaming revisions %v\n", err)
+		}
+
+		fmt.Printf("Blog revision %v: %v\n", res.GetBlog().GetRevision(), res.GetBlog().GetTitle())
+	}
+
+	//Show what changed since the first revision
+	diffRes, err := c.DiffBlogRevisions(context.Background(), &blogpb.DiffBlogRevisionsRequest{
+		BlogId:       blogID,
+		FromRevision: 1,
+		ToRevision:   3,
+	})
+	if err != nil {
+		fmt.Printf("Error on diff revisions: %v\n", err)
+	}
+
+	fmt.Printf("Blog diff:\n%v", diffRes.GetDiff())
+
+	//Restore the first revision
+	rollbackRes, err := c.RollbackBlog(context.Background(), &blogpb.RollbackBlogRequest{BlogId: blogID, Revision: 1})
+	if err != nil {
+		fmt.Printf("Error on rollback blog: %v\n", err)
+	}
+
+	fmt.Printf("Blog rolled back: %v\n", rollbackRes.GetBlog())
+}
+
+func doComments(c blogpb.CommentServiceClient, blogID string) {
+	fmt.Println("Commenting the blog")
+	createRes, err := c.CreateComment(context.Background(), &blogpb.CreateCommentRequest{
+		Comment: &blogpb.Comment{BlogId: blogID, AuthorId: "AuthorString", Content: "Nice blog"},
+	})
+	if err != nil {
+		log.Fatalf("Error on creating comment %v", err)
+	}
+
+	//Reply to the first comment
+	_, err = c.CreateComment(context.Background(), &blogpb.CreateCommentRequest{
+		Comment: &blogpb.Comment{
+			BlogId:          blogID,
+			ParentCommentId: createRes.GetComment().GetId(),
+			AuthorId:        "AuthorString the better",
+			Content:         "Thanks",
+		},
+	})
+	if err != nil {
+		log.Fatalf("Error on replying to comment %v", err)
+	}
 
+	stream, err := c.ListComments(context.Background(), &blogpb.ListCommentsRequest{BlogId: blogID})
+	if err != nil {
+		log.Fatalf("Error on listing comments %v", err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			log.Fatalf("Error while streaming blog %v\n", err)
+			log.Fatalf("Error while streaming comments %v", err)
 		}
 
-		fmt.Printf("Blog list item streamed: %v \n", blog)
+		fmt.Printf("Comment: %v\n", res.GetComment())
+	}
+
+	deleteRes, err := c.DeleteComment(context.Background(), &blogpb.DeleteCommentRequest{CommentId: createRes.GetComment().GetId()})
+	if err != nil {
+		log.Fatalf("Error on deleting comment %v", err)
+	}
+
+	fmt.Printf("Deleted comments: %v\n", deleteRes.GetDeletedCount())
+}
+
+func doSchedule(c blogpb.BlogServiceClient, blogID string) {
+	//Taken down until it is published again in an hour by the server
+	unpublishRes, err := c.UnpublishBlog(context.Background(), &blogpb.UnpublishBlogRequest{BlogId: blogID})
+	if err != nil {
+		log.Fatalf("Error on unpublishing blog: %v", err)
+	}
+	fmt.Printf("Blog unpublished: %v\n", unpublishRes.GetBlog().GetStatus())
+
+	scheduleRes, err := c.PublishBlog(context.Background(), &blogpb.PublishBlogRequest{
+		BlogId:      blogID,
+		PublishTime: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	if err != nil {
+		log.Fatalf("Error on scheduling blog: %v", err)
+	}
+	fmt.Printf("Blog scheduled: %v %v\n", scheduleRes.GetBlog().GetStatus(), scheduleRes.GetBlog().GetPublishTime())
+
+	//Changed the mind, keep it only in the archive
+	archiveRes, err := c.UnpublishBlog(context.Background(), &blogpb.UnpublishBlogRequest{BlogId: blogID, Archive: true})
+	if err != nil {
+		log.Fatalf("Error on archiving blog: %v", err)
+	}
+	fmt.Printf("Blog archived: %v\n", archiveRes.GetBlog().GetStatus())
+
+	//Archived blogs can not be archived again
+	if _, err := c.UnpublishBlog(context.Background(), &blogpb.UnpublishBlogRequest{BlogId: blogID, Archive: true}); err != nil {
+		fmt.Printf("Archive rejected: %v\n", errorDetails(err))
 	}
 }
+
+func doAuthors(c blogpb.AuthorServiceClient) (string, string) {
+	fmt.Println("Creating authors")
+	createRes, err := c.CreateAuthor(context.Background(), &blogpb.CreateAuthorRequest{
+		Author: &blogpb.Author{DisplayName: "AuthorString", Email: "author@example.com"},
+	})
+	if err != nil {
+		log.Fatalf("Error on creating author: %v", err)
+	}
+	betterRes, err := c.CreateAuthor(context.Background(), &blogpb.CreateAuthorRequest{
+		Author: &blogpb.Author{DisplayName: "AuthorString the better"},
+	})
+	if err != nil {
+		log.Fatalf("Error on creating author: %v", err)
+	}
+
+	//Set only the bio
+	updateRes, err := c.UpdateAuthor(context.Background(), &blogpb.UpdateAuthorRequest{
+		Author:     &blogpb.Author{Id: createRes.GetAuthor().GetId(), Bio: "Writes about Go"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"bio"}},
+	})
+	if err != nil {
+		fmt.Printf("Error on update author: %v\n", err)
+	}
+
+	fmt.Printf("Author updated: %v\n", updateRes.GetAuthor())
+
+	stream, err := c.ListAuthors(context.Background(), &blogpb.ListAuthorsRequest{})
+	if err != nil {
+		log.Fatalf("Error on listing authors %v", err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Error while streaming authors %v\n", err)
+		}
+
+		fmt.Printf("Author: %v\n", res.GetAuthor())
+	}
+
+	return createRes.GetAuthor().GetId(), betterRes.GetAuthor().GetId()
+}
+
+func doBatch(c blogpb.BlogServiceClient, authorID string) {
+	fmt.Println("Creating blogs in batch")
+	createRes, err := c.BatchCreateBlogs(context.Background(), &blogpb.BatchCreateBlogsRequest{
+		Blogs: []*blogpb.Blog{
+			{AuthorId: authorID, Title: "Batch blog 1", Content: "First", Tags: []string{"go", "grpc"}, Category: "Programming"},
+			{AuthorId: authorID, Title: "Batch blog 2", Content: "Second", Tags: []string{"Go", "mongodb"}, Category: "Databases"},
+			{AuthorId: "AuthorString", Title: "Batch blog of unknown author"},
+		},
+	})
+	if err != nil {
+		log.Fatalf("Error on batch create %v", err)
+	}
+
+	ids := []string{"not an id"}
+	for _, result := range createRes.GetResults() {
+		fmt.Printf("Batch created %v %v\n", result.GetStatus(), result.GetBlog())
+		if result.GetBlog() == nil {
+			continue
+		}
+		ids = append(ids, result.GetBlog().GetId())
+
+		if _, err := c.PublishBlog(context.Background(), &blogpb.PublishBlogRequest{BlogId: result.GetBlog().GetId()}); err != nil {
+			log.Fatalf("Error on publishing blog: %v", err)
+		}
+	}
+
+	getRes, err := c.BatchGetBlogs(context.Background(), &blogpb.BatchGetBlogsRequest{BlogIds: ids})
+	if err != nil {
+		log.Fatalf("Error on batch get %v", err)
+	}
+
+	for _, result := range getRes.GetResults() {
+		fmt.Printf("Batch read %v %v\n", result.GetStatus(), result.GetBlog())
+	}
+
+	//Import streams any number of blogs
+	stream, err := c.ImportBlogs(context.Background())
+	if err != nil {
+		log.Fatalf("Error on import %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		blog := &blogpb.Blog{AuthorId: authorID, Title: fmt.Sprintf("Imported blog %d", i)}
+		if err := stream.Send(&blogpb.ImportBlogsRequest{Blog: blog}); err != nil {
+			log.Fatalf("Error on sending import %v", err)
+		}
+	}
+	importRes, err := stream.CloseAndRecv()
+	if err != nil {
+		log.Fatalf("Error on import %v", err)
+	}
+
+	fmt.Printf("Imported %d blogs, failures %v\n", importRes.GetCreatedCount(), importRes.GetFailures())
+
+	//Only the blogs of the author
+	listStream, err := c.ListBlogsByAuthor(context.Background(), &blogpb.ListBlogsByAuthorRequest{AuthorId: authorID, PageSize: 3})
+	if err != nil {
+		log.Fatalf("Error on listing blogs by author %v", err)
+	}
+	for {
+		res, err := listStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Error while streaming blogs by author %v\n", err)
+		}
+
+		fmt.Printf("Blog of the author: %v\n", res.GetBlog().GetTitle())
+	}
+
+	doTags(c)
+
+	deleteRes, err := c.BatchDeleteBlogs(context.Background(), &blogpb.BatchDeleteBlogsRequest{BlogIds: ids, Purge: true})
+	if err != nil {
+		log.Fatalf("Error on batch delete %v", err)
+	}
+
+	for _, result := range deleteRes.GetResults() {
+		fmt.Printf("Batch deleted %v %v\n", result.GetStatus(), result.GetBlog().GetId())
+	}
+}
+
+func doTags(c blogpb.BlogServiceClient) {
+	//Blogs with both tags
+	listBlogReq := &blogpb.ListBlogRequest{Tags: []string{"go", "grpc"}, TagMatch: blogpb.ListBlogRequest_TAGS_ALL}
+	stream, err := c.ListBlog(context.Background(), listBlogReq)
+	if err != nil {
+		log.Fatalf("Error on listing blogs by tags %v", err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Error while streaming blogs by tags %v\n", err)
+		}
+
+		fmt.Printf("Blog tagged go and grpc: %v %v\n", res.GetBlog().GetTitle(), res.GetBlog().GetTags())
+	}
+
+	facetsRes, err := c.GetTagFacets(context.Background(), &blogpb.GetTagFacetsRequest{Tags: []string{"go"}})
+	if err != nil {
+		log.Fatalf("Error on getting tag facets %v", err)
+	}
+
+	fmt.Printf("Tags of go blogs: %v\n", facetsRes.GetTags())
+	fmt.Printf("Categories of go blogs: %v\n", facetsRes.GetCategories())
+}
+
+func watchBlogs(c blogpb.BlogServiceClient) {
+	resumeToken := ""
+	for {
+		stream, err := c.WatchBlogs(context.Background(), &blogpb.WatchBlogsRequest{ResumeToken: resumeToken})
+		if err != nil {
+			log.Fatalf("Error on watching blogs %v", err)
+		}
+
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				//Reconnect from the last event
+				fmt.Printf("Watch stopped: %v\n", err)
+				break
+			}
+
+			fmt.Printf("Blog event %v: %v\n", event.GetType(), event.GetBlog().GetId())
+			resumeToken = event.GetResumeToken()
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
+// errorDetails describes the status error with its google.rpc details
+func errorDetails(err error) string {
+	details, _ := rpcerror.FromError(err)
+	return details.String()
+}
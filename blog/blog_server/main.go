@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogserver"
+	"github.com/KestutisKazlauskas/grpc-go/config"
+	"github.com/KestutisKazlauskas/grpc-go/grpcserver"
+
+	"golang.org/x/net/context"
+)
+
+// serverConfig is the blog_server configuration. It is loaded with the BLOG
+// environment prefix, see the config package
+type serverConfig struct {
+	config.Server
+	blogserver.Config
+}
+
+func main() {
+	// if somthing crash in go code
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	cfg := &serverConfig{
+		Server: config.DefaultServer(),
+		Config: blogserver.DefaultConfig(),
+	}
+	if err := config.Load("BLOG", cfg, os.Args[1:]); err != nil {
+		log.Fatalf("Config error %v", err)
+	}
+
+	blog, err := blogserver.New(cfg.Config)
+	if err != nil {
+		log.Fatalf("Store error %v", err)
+	}
+
+	fmt.Println("Blog service started")
+	listen, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		log.Fatalf("Failed to listen %v", err)
+	}
+
+	s, err := grpcserver.New(cfg.Server)
+	if err != nil {
+		log.Fatalf("Server error %v", err)
+	}
+	blog.Register(s.Context(), s.Server)
+	blog.WatchHealth(s.Health)
+	s.Policy.SetOwners(blog.Owners())
+
+	fmt.Printf("Starting server on %s...\n", cfg.Listen)
+	if err := s.ServeUntilSignal(listen, cfg.ShutdownTimeout); err != nil {
+		log.Fatalf("Failder to server %v", err)
+	}
+
+	// The store is closed after the pending RPCs finish
+	fmt.Println("Closing the store")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	blog.Close(ctx)
+	fmt.Println("Program ended")
+}
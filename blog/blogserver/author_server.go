@@ -0,0 +1,62 @@
+package blogserver
+
+import (
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+)
+
+type authorServer struct {
+	store AuthorStore
+}
+
+func (s *authorServer) CreateAuthor(ctx context.Context, req *blogpb.CreateAuthorRequest) (*blogpb.CreateAuthorResponse, error) {
+	author, err := s.store.CreateAuthor(ctx, req.GetAuthor())
+	if err != nil {
+		return nil, storeError(err, "Internal error")
+	}
+
+	return &blogpb.CreateAuthorResponse{
+		Author: author,
+	}, nil
+}
+
+func (s *authorServer) GetAuthor(ctx context.Context, req *blogpb.GetAuthorRequest) (*blogpb.GetAuthorResponse, error) {
+	author, err := s.store.ReadAuthor(ctx, req.GetAuthorId())
+	if err != nil {
+		return nil, storeError(err, "Cannot read author")
+	}
+
+	return &blogpb.GetAuthorResponse{
+		Author: author,
+	}, nil
+}
+
+func (s *authorServer) UpdateAuthor(ctx context.Context, req *blogpb.UpdateAuthorRequest) (*blogpb.UpdateAuthorResponse, error) {
+	fields, err := updateFields(req.GetUpdateMask(), authorUpdatableFields)
+	if err != nil {
+		return nil, badRequest("INVALID_UPDATE_MASK", "Invalid update mask", "update_mask", err)
+	}
+
+	author, err := s.store.UpdateAuthor(ctx, req.GetAuthor(), fields)
+	if err != nil {
+		return nil, storeError(err, "Cannot update author")
+	}
+
+	return &blogpb.UpdateAuthorResponse{
+		Author: author,
+	}, nil
+}
+
+func (s *authorServer) ListAuthors(req *blogpb.ListAuthorsRequest, stream blogpb.AuthorService_ListAuthorsServer) error {
+	err := s.store.ListAuthors(stream.Context(), func(author *blogpb.Author) error {
+		return stream.Send(&blogpb.ListAuthorsResponse{
+			Author: author,
+		})
+	})
+	if err != nil {
+		return storeError(err, "Error on reading authors")
+	}
+
+	return nil
+}
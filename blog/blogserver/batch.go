@@ -0,0 +1,124 @@
+package blogserver
+
+import (
+	"io"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blogs written to the store at once by ImportBlogs
+const importChunkSize = 500
+
+// itemStatus converts the store error of one batch item
+func itemStatus(err error, message string) *blogpb.ItemStatus {
+	if err == nil {
+		return &blogpb.ItemStatus{Code: int32(codes.OK)}
+	}
+
+	st := status.Convert(storeError(err, message))
+	return &blogpb.ItemStatus{
+		Code:    int32(st.Code()),
+		Message: st.Message(),
+	}
+}
+
+func batchResults(results []*batchResult, message string) []*blogpb.BatchBlogResult {
+	res := make([]*blogpb.BatchBlogResult, len(results))
+	for i, result := range results {
+		res[i] = &blogpb.BatchBlogResult{Status: itemStatus(result.Err, message)}
+		if result.Err == nil {
+			res[i].Blog = result.Blog
+		}
+	}
+
+	return res
+}
+
+func (s *server) BatchCreateBlogs(ctx context.Context, req *blogpb.BatchCreateBlogsRequest) (*blogpb.BatchCreateBlogsResponse, error) {
+	results, err := s.store.CreateMany(ctx, req.GetBlogs())
+	if err != nil {
+		return nil, storeError(err, "Internal error")
+	}
+
+	return &blogpb.BatchCreateBlogsResponse{
+		Results: batchResults(results, "Internal error"),
+	}, nil
+}
+
+func (s *server) BatchGetBlogs(ctx context.Context, req *blogpb.BatchGetBlogsRequest) (*blogpb.BatchGetBlogsResponse, error) {
+	results, err := s.store.ReadMany(ctx, req.GetBlogIds())
+	if err != nil {
+		return nil, storeError(err, "Cannot read blogs")
+	}
+
+	return &blogpb.BatchGetBlogsResponse{
+		Results: batchResults(results, "Cannot read blog"),
+	}, nil
+}
+
+func (s *server) BatchDeleteBlogs(ctx context.Context, req *blogpb.BatchDeleteBlogsRequest) (*blogpb.BatchDeleteBlogsResponse, error) {
+	results, err := s.store.DeleteMany(ctx, req.GetBlogIds(), req.GetPurge())
+	if err != nil {
+		return nil, storeError(err, "Error on deleting documents")
+	}
+
+	return &blogpb.BatchDeleteBlogsResponse{
+		Results: batchResults(results, "Error on deleting document"),
+	}, nil
+}
+
+func (s *server) ImportBlogs(stream blogpb.BlogService_ImportBlogsServer) error {
+	res := &blogpb.ImportBlogsResponse{}
+	var chunk []*blogpb.Blog
+	var index int64
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		results, err := s.store.CreateMany(stream.Context(), chunk)
+		if err != nil {
+			return storeError(err, "Internal error")
+		}
+
+		for i, result := range results {
+			if result.Err != nil {
+				res.Failures = append(res.Failures, &blogpb.ImportFailure{
+					Index:  index + int64(i),
+					Status: itemStatus(result.Err, "Internal error"),
+				})
+				continue
+			}
+			res.CreatedCount++
+		}
+
+		index += int64(len(chunk))
+		chunk = chunk[:0]
+		return nil
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			if err := flush(); err != nil {
+				return err
+			}
+			return stream.SendAndClose(res)
+		}
+		if err != nil {
+			return err
+		}
+
+		chunk = append(chunk, req.GetBlog())
+		if len(chunk) >= importChunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
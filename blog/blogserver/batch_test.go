@@ -0,0 +1,79 @@
+package blogserver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+)
+
+// missingID is a valid id of no blog
+const missingID = "000000000000000000000000"
+
+// resultCodes returns the item codes of the batch results, only the OK
+// items have a blog
+func resultCodes(t *testing.T, results []*blogpb.BatchBlogResult) []codes.Code {
+	t.Helper()
+	var got []codes.Code
+	for i, result := range results {
+		code := codes.Code(result.GetStatus().GetCode())
+		if (code == codes.OK) != (result.GetBlog() != nil) {
+			t.Errorf("result %d = %v with blog %v", i, code, result.GetBlog())
+		}
+		got = append(got, code)
+	}
+
+	return got
+}
+
+func TestBatchPartialFailures(t *testing.T) {
+	s, author := newTestServer(t)
+	ctx := context.Background()
+	blog := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "title", Content: "content"})
+
+	created, err := s.BatchCreateBlogs(ctx, &blogpb.BatchCreateBlogsRequest{Blogs: []*blogpb.Blog{
+		{AuthorId: author, Title: "a"},
+		{AuthorId: "unknown", Title: "b"},
+		{AuthorId: author, Title: "c"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resultCodes(t, created.GetResults()), []codes.Code{codes.OK, codes.FailedPrecondition, codes.OK}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BatchCreateBlogs() codes = %v, want %v", got, want)
+	}
+
+	ids := []string{blog.GetId(), missingID, "not-an-id", created.GetResults()[2].GetBlog().GetId()}
+	read, err := s.BatchGetBlogs(ctx, &blogpb.BatchGetBlogsRequest{BlogIds: ids})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resultCodes(t, read.GetResults()), []codes.Code{codes.OK, codes.NotFound, codes.InvalidArgument, codes.OK}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BatchGetBlogs() codes = %v, want %v", got, want)
+	}
+	if title := read.GetResults()[3].GetBlog().GetTitle(); title != "c" {
+		t.Errorf("BatchGetBlogs() blog 3 = %q, want c", title)
+	}
+
+	deleted, err := s.BatchDeleteBlogs(ctx, &blogpb.BatchDeleteBlogsRequest{BlogIds: ids})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resultCodes(t, deleted.GetResults()), []codes.Code{codes.OK, codes.NotFound, codes.InvalidArgument, codes.OK}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BatchDeleteBlogs() codes = %v, want %v", got, want)
+	}
+
+	// the failed items do not stop the others
+	for _, id := range []string{ids[0], ids[3]} {
+		got, err := s.store.Read(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.GetDeleteTime() == nil {
+			t.Errorf("blog %s is not deleted", id)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package blogserver
+
+import (
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+)
+
+type commentServer struct {
+	store CommentStore
+}
+
+func (s *commentServer) CreateComment(ctx context.Context, req *blogpb.CreateCommentRequest) (*blogpb.CreateCommentResponse, error) {
+	comment, err := s.store.CreateComment(ctx, req.GetComment())
+	if err != nil {
+		return nil, storeError(err, "Internal error")
+	}
+
+	return &blogpb.CreateCommentResponse{
+		Comment: comment,
+	}, nil
+}
+
+func (s *commentServer) ListComments(req *blogpb.ListCommentsRequest, stream blogpb.CommentService_ListCommentsServer) error {
+	err := s.store.ListComments(stream.Context(), req.GetBlogId(), func(comment *blogpb.Comment) error {
+		return stream.Send(&blogpb.ListCommentsResponse{
+			Comment: comment,
+		})
+	})
+	if err != nil {
+		return storeError(err, "Error on reading comments")
+	}
+
+	return nil
+}
+
+func (s *commentServer) DeleteComment(ctx context.Context, req *blogpb.DeleteCommentRequest) (*blogpb.DeleteCommentResponse, error) {
+	count, err := s.store.DeleteComment(ctx, req.GetCommentId())
+	if err != nil {
+		return nil, storeError(err, "Error on deleting comment")
+	}
+
+	return &blogpb.DeleteCommentResponse{
+		CommentId:    req.GetCommentId(),
+		DeletedCount: count,
+	}, nil
+}
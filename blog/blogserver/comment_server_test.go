@@ -0,0 +1,69 @@
+package blogserver
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+)
+
+func TestDeleteCommentReplies(t *testing.T) {
+	s, author := newTestServer(t)
+	store := s.store.(Store)
+	comments := &commentServer{store: store}
+	ctx := context.Background()
+	blog := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "title", Content: "content"})
+	other := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "other", Content: "content"})
+
+	ids := map[string]string{}
+	create := func(name, blogID, parent string) {
+		t.Helper()
+		res, err := comments.CreateComment(ctx, &blogpb.CreateCommentRequest{Comment: &blogpb.Comment{
+			BlogId: blogID, ParentCommentId: ids[parent], AuthorId: author, Content: name,
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[name] = res.GetComment().GetId()
+	}
+	create("root", blog.GetId(), "")
+	create("reply", blog.GetId(), "root")
+	create("reply to reply", blog.GetId(), "reply")
+	create("second reply", blog.GetId(), "root")
+	create("other root", blog.GetId(), "")
+	create("other reply", blog.GetId(), "other root")
+
+	// a reply must be in the blog of its parent
+	_, err := comments.CreateComment(ctx, &blogpb.CreateCommentRequest{Comment: &blogpb.Comment{
+		BlogId: other.GetId(), ParentCommentId: ids["root"], AuthorId: author, Content: "elsewhere",
+	}})
+	wantReason(t, err, codes.InvalidArgument, "PARENT_COMMENT_NOT_FOUND")
+
+	res, err := comments.DeleteComment(ctx, &blogpb.DeleteCommentRequest{CommentId: ids["root"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetDeletedCount() != 4 {
+		t.Errorf("DeleteComment() deleted %d, want the comment and its 3 replies", res.GetDeletedCount())
+	}
+
+	var left []string
+	err = store.ListComments(ctx, blog.GetId(), func(comment *blogpb.Comment) error {
+		left = append(left, comment.GetContent())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(left)
+	if want := []string{"other reply", "other root"}; !reflect.DeepEqual(left, want) {
+		t.Errorf("comments left = %q, want %q", left, want)
+	}
+
+	_, err = comments.DeleteComment(ctx, &blogpb.DeleteCommentRequest{CommentId: ids["reply"]})
+	wantReason(t, err, codes.NotFound, "COMMENT_NOT_FOUND")
+}
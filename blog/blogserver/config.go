@@ -0,0 +1,60 @@
+package blogserver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config is the blog service configuration, see the config package
+type Config struct {
+	Store            string        `config:"store" usage:"Blog storage backend: mongo or memory"`
+	Mongo            MongoConfig   `config:"mongo"`
+	DeletedRetention time.Duration `config:"deleted-retention" usage:"How long soft deleted blogs are kept"`
+	PurgeInterval    time.Duration `config:"purge-interval" usage:"How often expired deleted blogs are purged"`
+	PublishInterval  time.Duration `config:"publish-interval" usage:"How often scheduled blogs are published when due"`
+	HealthInterval   time.Duration `config:"health-interval" usage:"How often the store is pinged for the health checks"`
+}
+
+// MongoConfig is the connection to mongodb. The credentials are kept out of
+// the URI so the password can come from the environment only
+type MongoConfig struct {
+	URI            string        `config:"uri" usage:"mongodb connection string"`
+	Username       string        `config:"username" usage:"mongodb user, no authentication if empty"`
+	Password       string        `config:"password" usage:"mongodb password"`
+	ConnectTimeout time.Duration `config:"connect-timeout" usage:"How long the connection and the index creation can take"`
+}
+
+// DefaultConfig returns the defaults of the blog service
+func DefaultConfig() Config {
+	return Config{
+		Store: "mongo",
+		Mongo: MongoConfig{
+			URI:            "mongodb://localhost:27017",
+			ConnectTimeout: 20 * time.Second,
+		},
+		DeletedRetention: 30 * 24 * time.Hour,
+		PurgeInterval:    time.Hour,
+		PublishInterval:  time.Minute,
+		HealthInterval:   10 * time.Second,
+	}
+}
+
+// Validate checks the store settings
+func (c *Config) Validate() error {
+	switch c.Store {
+	case "mongo":
+		if c.Mongo.URI == "" {
+			return errors.New("mongo.uri is required with the mongo store")
+		}
+	case "memory":
+	default:
+		return fmt.Errorf("unknown store %q, use mongo or memory", c.Store)
+	}
+
+	if c.DeletedRetention <= 0 || c.PurgeInterval <= 0 || c.PublishInterval <= 0 || c.HealthInterval <= 0 || c.Mongo.ConnectTimeout <= 0 {
+		return errors.New("deleted-retention, purge-interval, publish-interval, health-interval and mongo.connect-timeout must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package blogserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+)
+
+const (
+	// unchanged lines shown around the changes
+	diffContext = 3
+	// the diff table has a cell for each pair of changed lines (8 bytes
+	// each, about 3MB), refuse bigger changes
+	maxDiffCells = 400000
+)
+
+// revisionLines is the text of a blog compared by DiffBlogRevisions
+func revisionLines(blog *blogpb.Blog) []string {
+	text := fmt.Sprintf("author_id: %s\ntitle: %s\ncategory: %s\ntags: %s\n\n%s",
+		blog.GetAuthorId(), blog.GetTitle(), blog.GetCategory(), strings.Join(blog.GetTags(), ", "), blog.GetContent())
+
+	return strings.Split(text, "\n")
+}
+
+// diffLine is one line of the edit script
+type diffLine struct {
+	// op is ' ' for unchanged, '-' for removed and '+' for added lines
+	op   byte
+	text string
+	// line numbers in from and to, counted from 0
+	fromLine, toLine int
+}
+
+// editScript turns from into to with the longest common subsequence of
+// lines. The unchanged lines at the start and the end are left out of the
+// table so only the changed middle counts against maxDiffCells
+func editScript(from, to []string) ([]diffLine, error) {
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix && from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+	fromMid, toMid := from[prefix:len(from)-suffix], to[prefix:len(to)-suffix]
+	if len(fromMid)*len(toMid) > maxDiffCells {
+		return nil, fmt.Errorf("changes of %d and %d lines are too big", len(fromMid), len(toMid))
+	}
+
+	script := make([]diffLine, 0, len(from)+len(toMid))
+	for i := 0; i < prefix; i++ {
+		script = append(script, diffLine{op: ' ', text: from[i], fromLine: i, toLine: i})
+	}
+
+	// lcs[i][j] is the common subsequence length of fromMid[i:] and toMid[j:]
+	lcs := make([][]int, len(fromMid)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(toMid)+1)
+	}
+	for i := len(fromMid) - 1; i >= 0; i-- {
+		for j := len(toMid) - 1; j >= 0; j-- {
+			if fromMid[i] == toMid[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(fromMid) || j < len(toMid) {
+		switch {
+		case i < len(fromMid) && j < len(toMid) && fromMid[i] == toMid[j]:
+			script = append(script, diffLine{op: ' ', text: fromMid[i], fromLine: prefix + i, toLine: prefix + j})
+			i++
+			j++
+		case j == len(toMid) || (i < len(fromMid) && lcs[i+1][j] >= lcs[i][j+1]):
+			script = append(script, diffLine{op: '-', text: fromMid[i], fromLine: prefix + i, toLine: prefix + j})
+			i++
+		default:
+			script = append(script, diffLine{op: '+', text: toMid[j], fromLine: prefix + i, toLine: prefix + j})
+			j++
+		}
+	}
+
+	for k := suffix; k > 0; k-- {
+		script = append(script, diffLine{op: ' ', text: from[len(from)-k], fromLine: len(from) - k, toLine: len(to) - k})
+	}
+
+	return script, nil
+}
+
+// hunkRange formats the start and length of a hunk side like diff -u
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// unifiedDiff returns the changes from the from lines to the to lines in
+// the unified format. Equal texts give an empty diff
+func unifiedDiff(fromName, toName string, from, to []string) (string, error) {
+	script, err := editScript(from, to)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for start := 0; start < len(script); {
+		// find the next change
+		for start < len(script) && script[start].op == ' ' {
+			start++
+		}
+		if start == len(script) {
+			break
+		}
+
+		// extend the hunk while the changes are close enough to share the context
+		first := start - diffContext
+		if first < 0 {
+			first = 0
+		}
+		end := start
+		for end < len(script) {
+			if script[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(script) && script[next].op == ' ' {
+				next++
+			}
+			if next == len(script) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		last := end + diffContext
+		if last > len(script) {
+			last = len(script)
+		}
+
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+		}
+
+		fromCount, toCount := 0, 0
+		for _, line := range script[first:last] {
+			if line.op != '+' {
+				fromCount++
+			}
+			if line.op != '-' {
+				toCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(script[first].fromLine, fromCount),
+			hunkRange(script[first].toLine, toCount),
+		)
+		for _, line := range script[first:last] {
+			b.WriteByte(line.op)
+			b.WriteString(line.text)
+			b.WriteByte('\n')
+		}
+
+		start = last
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,63 @@
+package blogserver
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(s string) []string { return strings.Split(s, "\n") }
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{"equal", "a\nb\nc", "a\nb\nc", ""},
+		{"changed line", "a\nb\nc", "a\nx\nc", "--- from\n+++ to\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n"},
+		{"added at end", "a\nb", "a\nb\nc", "--- from\n+++ to\n@@ -1,2 +1,3 @@\n a\n b\n+c\n"},
+		{"removed at start", "a\nb", "b", "--- from\n+++ to\n@@ -1,2 +1 @@\n-a\n b\n"},
+		{"from empty", "", "a", "--- from\n+++ to\n@@ -1 +1 @@\n-\n+a\n"},
+		{
+			"two hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12",
+			"x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny",
+			"--- from\n+++ to\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unifiedDiff("from", "to", lines(tt.from), lines(tt.to))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffTooBig(t *testing.T) {
+	var from, to []string
+	for i := 0; i < 1000; i++ {
+		from = append(from, "from "+strconv.Itoa(i))
+		to = append(to, "to "+strconv.Itoa(i))
+	}
+	if _, err := unifiedDiff("from", "to", from, to); err == nil {
+		t.Error("unifiedDiff() of 1000 changed lines = nil error, want too big")
+	}
+
+	// the same lines around a small change do not count
+	long := make([]string, 100000)
+	for i := range long {
+		long[i] = strconv.Itoa(i)
+	}
+	changed := append([]string(nil), long...)
+	changed[50000] = "changed"
+	if _, err := unifiedDiff("from", "to", long, changed); err != nil {
+		t.Errorf("unifiedDiff() of a long text with one change = %v", err)
+	}
+}
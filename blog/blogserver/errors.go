@@ -0,0 +1,58 @@
+package blogserver
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+)
+
+// storeErrors maps the store errors to the errors sent to the clients
+var storeErrors = []rpcerror.Rule{
+	{Target: errInvalidID, Code: codes.InvalidArgument, Reason: "INVALID_ID", Message: "Cannot parse ID"},
+	{Target: errBlogNotFound, Code: codes.NotFound, Reason: "BLOG_NOT_FOUND", Message: "Cannot find blog", ResourceType: "blog"},
+	{Target: errCommentNotFound, Code: codes.NotFound, Reason: "COMMENT_NOT_FOUND", Message: "Cannot find comment", ResourceType: "comment"},
+	{Target: errParentNotFound, Code: codes.InvalidArgument, Reason: "PARENT_COMMENT_NOT_FOUND", Message: "Cannot reply, the parent comment is not in the blog", ResourceType: "comment"},
+	{Target: errBlogNotDeleted, Code: codes.FailedPrecondition, Reason: "BLOG_NOT_DELETED", Message: "Cannot undelete blog, it is not deleted", ResourceType: "blog"},
+	{Target: errInvalidResumeToken, Code: codes.InvalidArgument, Reason: "INVALID_RESUME_TOKEN", Message: "Cannot parse resume token"},
+	{Target: errResumeTokenExpired, Code: codes.OutOfRange, Reason: "RESUME_TOKEN_EXPIRED", Message: "Cannot resume, read the blogs again"},
+	{Target: errWatcherTooSlow, Code: codes.Unavailable, Reason: "WATCHER_TOO_SLOW", Message: "Resume with the last token"},
+	{Target: errAuthorNotFound, Code: codes.NotFound, Reason: "AUTHOR_NOT_FOUND", Message: "Cannot find author", ResourceType: "author"},
+	{Target: errUnknownAuthor, Code: codes.FailedPrecondition, Reason: "UNKNOWN_AUTHOR", Message: "Create the author first", ResourceType: "author"},
+	{Target: errInvalidStatus, Code: codes.FailedPrecondition, Reason: "INVALID_STATUS_CHANGE", Message: "Cannot change blog status", ResourceType: "blog"},
+	{Target: errRevisionNotFound, Code: codes.NotFound, Reason: "REVISION_NOT_FOUND", Message: "Cannot find blog revision", ResourceType: "blog"},
+	{Target: errRevisionMismatch, Code: codes.Aborted, Reason: "REVISION_MISMATCH", Message: "Blog was changed, read it again", ResourceType: "blog"},
+	{Target: errUnavailable, Code: codes.Unavailable, Reason: "STORAGE_UNAVAILABLE", Message: "Storage is unavailable, try again later", RetryDelay: storageRetryDelay},
+	{Target: errStorageTimeout, Code: codes.DeadlineExceeded, Reason: "STORAGE_TIMEOUT", Message: "Storage did not answer in time"},
+	{Target: context.DeadlineExceeded, Code: codes.DeadlineExceeded, Reason: "DEADLINE_EXCEEDED", Message: "Request deadline exceeded"},
+	{Target: context.Canceled, Code: codes.Canceled, Reason: "CANCELED", Message: "Request was canceled"},
+}
+
+// storageRetryDelay is the retry hint sent with the storage outage errors
+const storageRetryDelay = time.Second
+
+// storeError converts store errors to grpc status errors with details.
+// message is sent for unexpected errors. Storage failures are only logged
+func storeError(err error, message string) error {
+	err = mongoError(err)
+	e := rpcerror.Map(err, storeErrors, errorID(err), message)
+	if e.Code == codes.Internal || errors.Is(err, errUnavailable) || errors.Is(err, errStorageTimeout) {
+		log.Printf("%s: %v", message, err)
+	}
+	if id := errorID(err); id != "" {
+		e.WithMetadata("id", id)
+	}
+
+	return e.Err()
+}
+
+// badRequest is an InvalidArgument error about the field of the request
+func badRequest(reason, message, field string, err error) error {
+	return rpcerror.New(codes.InvalidArgument, reason, message).
+		WithViolation(field, err.Error()).
+		Err()
+}
@@ -0,0 +1,144 @@
+package blogserver
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+	"golang.org/x/net/context"
+)
+
+const (
+	// events kept by eventHub for resuming watchers
+	eventHistorySize = 1000
+	// events buffered for one watcher before it is dropped as too slow
+	watcherBufferSize = 100
+)
+
+var (
+	// errInvalidResumeToken is returned by Watch if the token can not be parsed
+	errInvalidResumeToken = errors.New("invalid resume token")
+	// errResumeTokenExpired is returned by Watch if events after the token
+	// are not kept anymore
+	errResumeTokenExpired = errors.New("resume token expired")
+	// errWatcherTooSlow is returned by Watch when the watcher does not keep up
+	// with the changes. It can resume with the last received token
+	errWatcherTooSlow = errors.New("watcher too slow")
+)
+
+// blogEvent is a change of a blog returned by BlogStore.Watch
+type blogEvent struct {
+	Type  blogpb.WatchBlogsResponse_EventType
+	Blog  *blogpb.Blog
+	Token string
+}
+
+// eventHub is an in process pub/sub of blog events for stores without
+// a native change feed. It keeps the last eventHistorySize events so
+// watchers can resume after reconnecting
+type eventHub struct {
+	mu       sync.Mutex
+	seq      int64
+	history  []*blogEvent
+	watchers map[chan *blogEvent]bool
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{
+		watchers: make(map[chan *blogEvent]bool),
+	}
+}
+
+// publish sends the event to all watchers. Watchers with a full buffer are
+// closed so a slow watcher never blocks the writes
+func (h *eventHub) publish(eventType blogpb.WatchBlogsResponse_EventType, blog *blogpb.Blog) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	event := &blogEvent{
+		Type:  eventType,
+		Blog:  cloneBlog(blog),
+		Token: strconv.FormatInt(h.seq, 10),
+	}
+
+	h.history = append(h.history, event)
+	if len(h.history) > eventHistorySize {
+		h.history = h.history[1:]
+	}
+
+	for ch := range h.watchers {
+		select {
+		case ch <- event:
+		default:
+			delete(h.watchers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe returns the events after the token and the channel with the next
+// events
+func (h *eventHub) subscribe(token string) ([]*blogEvent, chan *blogEvent, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var backlog []*blogEvent
+	if token != "" {
+		seq, err := strconv.ParseInt(token, 10, 64)
+		if err != nil || seq < 0 || seq > h.seq {
+			return nil, nil, errInvalidResumeToken
+		}
+
+		first := h.seq - int64(len(h.history)) + 1
+		if seq+1 < first {
+			return nil, nil, errResumeTokenExpired
+		}
+		backlog = append(backlog, h.history[seq+1-first:]...)
+	}
+
+	ch := make(chan *blogEvent, watcherBufferSize)
+	h.watchers[ch] = true
+
+	return backlog, ch, nil
+}
+
+func (h *eventHub) unsubscribe(ch chan *blogEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.watchers[ch] {
+		delete(h.watchers, ch)
+		close(ch)
+	}
+}
+
+// watch calls fn for the events after the token until ctx is done
+func (h *eventHub) watch(ctx context.Context, token string, fn func(*blogEvent) error) error {
+	backlog, ch, err := h.subscribe(token)
+	if err != nil {
+		return err
+	}
+	defer h.unsubscribe(ch)
+
+	for _, event := range backlog {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-ch:
+			if !ok {
+				return errWatcherTooSlow
+			}
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package blogserver
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+)
+
+// publishEvents publishes n updates of a blog with the event number as title
+func publishEvents(h *eventHub, n int) {
+	for i := 0; i < n; i++ {
+		h.publish(blogpb.WatchBlogsResponse_UPDATED, &blogpb.Blog{Id: "blog", Title: strconv.FormatInt(h.seq+1, 10)})
+	}
+}
+
+func TestEventHubResume(t *testing.T) {
+	h := newEventHub()
+	// the history keeps the events 6 to 1005
+	publishEvents(h, eventHistorySize+5)
+
+	tests := []struct {
+		name      string
+		token     string
+		wantFirst string
+		wantCount int
+		wantErr   error
+	}{
+		{"no token", "", "", 0, nil},
+		{"last event", "1005", "", 0, nil},
+		{"recent event", "1000", "1001", 5, nil},
+		{"before the oldest kept event", "5", "6", eventHistorySize, nil},
+		{"expired", "4", "", 0, errResumeTokenExpired},
+		{"zero", "0", "", 0, errResumeTokenExpired},
+		{"after the last event", "1006", "", 0, errInvalidResumeToken},
+		{"negative", "-1", "", 0, errInvalidResumeToken},
+		{"not a number", "x", "", 0, errInvalidResumeToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backlog, ch, err := h.subscribe(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("subscribe(%q) = %v, want %v", tt.token, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer h.unsubscribe(ch)
+
+			if len(backlog) != tt.wantCount {
+				t.Fatalf("backlog has %d events, want %d", len(backlog), tt.wantCount)
+			}
+			if tt.wantCount == 0 {
+				return
+			}
+			if first := backlog[0]; first.Token != tt.wantFirst || first.Blog.GetTitle() != tt.wantFirst {
+				t.Errorf("first event = %v %q, want token %s", first.Token, first.Blog.GetTitle(), tt.wantFirst)
+			}
+			if last := backlog[len(backlog)-1]; last.Token != "1005" {
+				t.Errorf("last event token = %s, want 1005", last.Token)
+			}
+		})
+	}
+}
+
+func TestEventHubWatch(t *testing.T) {
+	h := newEventHub()
+	publishEvents(h, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var tokens []string
+	done := make(chan error)
+	go func() {
+		done <- h.watch(ctx, "1", func(event *blogEvent) error {
+			tokens = append(tokens, event.Token)
+			if len(tokens) == 3 {
+				cancel()
+			}
+			return nil
+		})
+	}()
+	// the backlog has 2 and 3, the watcher gets 4 live or from its buffer
+	publishEvents(h, 1)
+
+	if err := <-done; err != context.Canceled {
+		t.Errorf("watch() = %v, want %v", err, context.Canceled)
+	}
+	if len(tokens) != 3 || tokens[0] != "2" || tokens[2] != "4" {
+		t.Errorf("tokens = %v, want [2 3 4]", tokens)
+	}
+}
+
+func TestEventHubSlowWatcher(t *testing.T) {
+	h := newEventHub()
+	_, ch, err := h.subscribe("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a full buffer drops the watcher without blocking the publisher
+	publishEvents(h, watcherBufferSize+1)
+	for i := 0; i < watcherBufferSize; i++ {
+		<-ch
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("channel of the slow watcher is open")
+	}
+	if len(h.watchers) != 0 {
+		t.Errorf("%d watchers, want none", len(h.watchers))
+	}
+	// unsubscribe after the drop does not close the channel twice
+	h.unsubscribe(ch)
+}
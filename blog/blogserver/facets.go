@@ -0,0 +1,45 @@
+package blogserver
+
+import (
+	"sort"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+)
+
+// facetList returns the counts most used first, then by value
+func facetList(counts map[string]int64) []*blogpb.Facet {
+	facets := make([]*blogpb.Facet, 0, len(counts))
+	for value, count := range counts {
+		facets = append(facets, &blogpb.Facet{Value: value, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+
+	return facets
+}
+
+func (s *server) GetTagFacets(ctx context.Context, req *blogpb.GetTagFacetsRequest) (*blogpb.GetTagFacetsResponse, error) {
+	query := &blogQuery{
+		AuthorID: req.GetAuthorId(),
+		Tags:     normalizeTags(req.GetTags()),
+		AllTags:  req.GetTagMatch() == blogpb.ListBlogRequest_TAGS_ALL,
+		Category: req.GetCategory(),
+		Statuses: publishedOnly,
+	}
+
+	facets, err := s.store.Facets(ctx, query)
+	if err != nil {
+		return nil, storeError(err, "Error on counting blog tags")
+	}
+
+	return &blogpb.GetTagFacetsResponse{
+		Tags:       facetList(facets.Tags),
+		Categories: facetList(facets.Categories),
+	}, nil
+}
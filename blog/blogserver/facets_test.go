@@ -0,0 +1,96 @@
+package blogserver
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+)
+
+// publishTestBlog creates the blog and publishes it now
+func publishTestBlog(t *testing.T, s *server, blog *blogpb.Blog) *blogpb.Blog {
+	t.Helper()
+	blog = createTestBlog(t, s, blog)
+	res, err := s.PublishBlog(context.Background(), &blogpb.PublishBlogRequest{BlogId: blog.GetId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.GetBlog()
+}
+
+// facetStrings returns the facets as "value:count"
+func facetStrings(facets []*blogpb.Facet) []string {
+	var got []string
+	for _, facet := range facets {
+		got = append(got, fmt.Sprintf("%s:%d", facet.GetValue(), facet.GetCount()))
+	}
+
+	return got
+}
+
+func TestGetTagFacets(t *testing.T) {
+	s, author := newTestServer(t)
+	publishTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "a", Tags: []string{"go", "grpc"}, Category: "dev"})
+	publishTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "b", Tags: []string{"Go"}, Category: "dev"})
+	publishTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "c", Tags: []string{"mongo", "grpc"}, Category: "db"})
+	publishTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "d"})
+	// the drafts are not counted
+	createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "draft", Tags: []string{"draft"}, Category: "dev"})
+
+	tests := []struct {
+		name           string
+		req            *blogpb.GetTagFacetsRequest
+		wantTags       []string
+		wantCategories []string
+	}{
+		{
+			"all",
+			&blogpb.GetTagFacetsRequest{},
+			[]string{"go:2", "grpc:2", "mongo:1"},
+			[]string{"dev:2", "db:1"},
+		},
+		{
+			"any tag",
+			&blogpb.GetTagFacetsRequest{Tags: []string{"GO", "mongo"}},
+			[]string{"go:2", "grpc:2", "mongo:1"},
+			[]string{"dev:2", "db:1"},
+		},
+		{
+			"all tags",
+			&blogpb.GetTagFacetsRequest{Tags: []string{"go", "grpc"}, TagMatch: blogpb.ListBlogRequest_TAGS_ALL},
+			[]string{"go:1", "grpc:1"},
+			[]string{"dev:1"},
+		},
+		{
+			"category",
+			&blogpb.GetTagFacetsRequest{Category: "db"},
+			[]string{"grpc:1", "mongo:1"},
+			[]string{"db:1"},
+		},
+		{
+			"other author",
+			&blogpb.GetTagFacetsRequest{AuthorId: "someone"},
+			nil,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.GetTagFacets(context.Background(), tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := facetStrings(res.GetTags()); !reflect.DeepEqual(got, tt.wantTags) {
+				t.Errorf("tags = %v, want %v", got, tt.wantTags)
+			}
+			if got := facetStrings(res.GetCategories()); !reflect.DeepEqual(got, tt.wantCategories) {
+				t.Errorf("categories = %v, want %v", got, tt.wantCategories)
+			}
+		})
+	}
+}
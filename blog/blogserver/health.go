@@ -0,0 +1,50 @@
+package blogserver
+
+import (
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// serviceNames are the services reported to the health checks
+var serviceNames = []string{"blog.BlogService", "blog.CommentService", "blog.AuthorService"}
+
+// watchHealth pings the store every interval until ctx is done and reports
+// the services NOT_SERVING while the ping fails
+func watchHealth(ctx context.Context, store Store, hs *health.Server, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	serving := true
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := store.Ping(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		status := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		if err != nil && serving {
+			log.Printf("Store is not available: %v", err)
+		} else if err == nil && !serving {
+			log.Println("Store is available again")
+		}
+		serving = err == nil
+		for _, name := range serviceNames {
+			hs.SetServingStatus(name, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
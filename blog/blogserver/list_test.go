@@ -0,0 +1,110 @@
+package blogserver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+)
+
+// drafts lists the new blogs, the lists show only the published ones by default
+var drafts = []blogpb.Blog_Status{blogpb.Blog_DRAFT}
+
+// listPage returns the titles of one page of drafts and the next page token
+func listPage(t *testing.T, s *server, req *blogpb.ListBlogRequest) ([]string, string) {
+	t.Helper()
+	req.Statuses = drafts
+	var titles []string
+	var next string
+	err := s.listBlogs(context.Background(), req, func(res *blogpb.ListBlogResponse) error {
+		titles = append(titles, res.GetBlog().GetTitle())
+		if res.GetNextPageToken() != "" {
+			next = res.GetNextPageToken()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return titles, next
+}
+
+func TestListBlogPaging(t *testing.T) {
+	s, author := newTestServer(t)
+	for _, title := range []string{"c", "a", "e", "b", "d"} {
+		createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: title, Content: "content"})
+	}
+
+	tests := []struct {
+		name  string
+		order blogpb.ListBlogRequest_Order
+		want  [][]string
+	}{
+		{"created asc", blogpb.ListBlogRequest_CREATED_ASC, [][]string{{"c", "a"}, {"e", "b"}, {"d"}}},
+		{"created desc", blogpb.ListBlogRequest_CREATED_DESC, [][]string{{"d", "b"}, {"e", "a"}, {"c"}}},
+		{"title asc", blogpb.ListBlogRequest_TITLE_ASC, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}},
+		{"title desc", blogpb.ListBlogRequest_TITLE_DESC, [][]string{{"e", "d"}, {"c", "b"}, {"a"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages [][]string
+			token := ""
+			for {
+				titles, next := listPage(t, s, &blogpb.ListBlogRequest{PageSize: 2, PageToken: token, Order: tt.order})
+				pages = append(pages, titles)
+				if next == "" {
+					break
+				}
+				if len(pages) > len(tt.want) {
+					t.Fatalf("more pages than %d", len(tt.want))
+				}
+				token = next
+			}
+			if !reflect.DeepEqual(pages, tt.want) {
+				t.Errorf("pages = %v, want %v", pages, tt.want)
+			}
+		})
+	}
+}
+
+func TestListBlogExactPage(t *testing.T) {
+	s, author := newTestServer(t)
+	for _, title := range []string{"a", "b"} {
+		createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: title, Content: "content"})
+	}
+
+	// a full last page has no next page token
+	titles, next := listPage(t, s, &blogpb.ListBlogRequest{PageSize: 2})
+	if len(titles) != 2 || next != "" {
+		t.Errorf("page = %v with token %q, want 2 blogs without a token", titles, next)
+	}
+}
+
+func TestListBlogInvalidRequest(t *testing.T) {
+	s, author := newTestServer(t)
+	createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "a", Content: "content"})
+	createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "b", Content: "content"})
+	_, token := listPage(t, s, &blogpb.ListBlogRequest{PageSize: 1})
+
+	tests := []struct {
+		name string
+		req  *blogpb.ListBlogRequest
+	}{
+		{"garbage token", &blogpb.ListBlogRequest{PageToken: "not-a-token", Statuses: drafts}},
+		{"token of another order", &blogpb.ListBlogRequest{PageSize: 1, PageToken: token, Statuses: drafts, Order: blogpb.ListBlogRequest_TITLE_ASC}},
+		{"token of other statuses", &blogpb.ListBlogRequest{PageSize: 1, PageToken: token}},
+		{"negative page size", &blogpb.ListBlogRequest{PageSize: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.listBlogs(context.Background(), tt.req, func(*blogpb.ListBlogResponse) error { return nil })
+			wantReason(t, err, codes.InvalidArgument, "INVALID_LIST_REQUEST")
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package blogserver
+
+import (
+	"sort"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/context"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func cloneAuthor(author *blogpb.Author) *blogpb.Author {
+	return proto.Clone(author).(*blogpb.Author)
+}
+
+// knownAuthor returns errUnknownAuthor if there is no author with the id.
+// The caller must hold the lock
+func (m *memoryStore) knownAuthor(id string) error {
+	if _, ok := m.authors[id]; !ok {
+		return withID(errUnknownAuthor, id)
+	}
+
+	return nil
+}
+
+func (m *memoryStore) CreateAuthor(ctx context.Context, author *blogpb.Author) (*blogpb.Author, error) {
+	now := timestamppb.Now()
+	data := &blogpb.Author{
+		Id:          primitive.NewObjectID().Hex(),
+		DisplayName: author.GetDisplayName(),
+		Email:       author.GetEmail(),
+		Bio:         author.GetBio(),
+		CreateTime:  now,
+		UpdateTime:  now,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.authors[data.Id] = data
+
+	return cloneAuthor(data), nil
+}
+
+func (m *memoryStore) ReadAuthor(ctx context.Context, id string) (*blogpb.Author, error) {
+	if _, err := parseObjectID(id); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.authors[id]
+	if !ok {
+		return nil, withID(errAuthorNotFound, id)
+	}
+
+	return cloneAuthor(data), nil
+}
+
+func (m *memoryStore) UpdateAuthor(ctx context.Context, author *blogpb.Author, fields []string) (*blogpb.Author, error) {
+	if _, err := parseObjectID(author.GetId()); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.authors[author.GetId()]
+	if !ok {
+		return nil, withID(errAuthorNotFound, author.GetId())
+	}
+	setAuthorFields(data, author, fields)
+	data.UpdateTime = timestamppb.Now()
+
+	return cloneAuthor(data), nil
+}
+
+func (m *memoryStore) ListAuthors(ctx context.Context, fn func(*blogpb.Author) error) error {
+	m.mu.RLock()
+	authors := make([]*blogpb.Author, 0, len(m.authors))
+	for _, author := range m.authors {
+		authors = append(authors, cloneAuthor(author))
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(authors, func(i, j int) bool { return authors[i].Id < authors[j].Id })
+
+	for _, author := range authors {
+		if err := fn(author); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package blogserver
+
+import (
+	"sort"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/context"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func cloneComment(comment *blogpb.Comment) *blogpb.Comment {
+	return proto.Clone(comment).(*blogpb.Comment)
+}
+
+// liveBlog returns errBlogNotFound if the blog is not found or deleted.
+// The caller must hold the lock
+func (m *memoryStore) liveBlog(blogID string) error {
+	if _, err := parseObjectID(blogID); err != nil {
+		return err
+	}
+
+	blog, ok := m.blogs[blogID]
+	if !ok || blog.GetDeleteTime() != nil {
+		return withID(errBlogNotFound, blogID)
+	}
+
+	return nil
+}
+
+// deleteBlogComments cascades the blog purge. The caller must hold the lock
+func (m *memoryStore) deleteBlogComments(blogID string) {
+	for id, comment := range m.comments {
+		if comment.GetBlogId() == blogID {
+			delete(m.comments, id)
+		}
+	}
+}
+
+func (m *memoryStore) CreateComment(ctx context.Context, comment *blogpb.Comment) (*blogpb.Comment, error) {
+	data := &blogpb.Comment{
+		Id:              primitive.NewObjectID().Hex(),
+		BlogId:          comment.GetBlogId(),
+		ParentCommentId: comment.GetParentCommentId(),
+		AuthorId:        comment.GetAuthorId(),
+		Content:         comment.GetContent(),
+		CreateTime:      timestamppb.Now(),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.liveBlog(data.BlogId); err != nil {
+		return nil, err
+	}
+	if data.ParentCommentId != "" {
+		parent, ok := m.comments[data.ParentCommentId]
+		if !ok || parent.GetBlogId() != data.BlogId {
+			return nil, withID(errParentNotFound, data.ParentCommentId)
+		}
+	}
+	m.comments[data.Id] = data
+
+	return cloneComment(data), nil
+}
+
+func (m *memoryStore) ListComments(ctx context.Context, blogID string, fn func(*blogpb.Comment) error) error {
+	m.mu.RLock()
+	if err := m.liveBlog(blogID); err != nil {
+		m.mu.RUnlock()
+		return err
+	}
+	var comments []*blogpb.Comment
+	for _, comment := range m.comments {
+		if comment.GetBlogId() == blogID {
+			comments = append(comments, cloneComment(comment))
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(comments, func(i, j int) bool { return comments[i].Id < comments[j].Id })
+
+	for _, comment := range comments {
+		if err := fn(comment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *memoryStore) ReadComment(ctx context.Context, id string) (*blogpb.Comment, error) {
+	if _, err := parseObjectID(id); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	comment, ok := m.comments[id]
+	if !ok {
+		return nil, withID(errCommentNotFound, id)
+	}
+
+	return cloneComment(comment), nil
+}
+
+func (m *memoryStore) DeleteComment(ctx context.Context, id string) (int64, error) {
+	if _, err := parseObjectID(id); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.comments[id]; !ok {
+		return 0, withID(errCommentNotFound, id)
+	}
+
+	// Delete the replies level by level
+	var count int64
+	parents := map[string]bool{id: true}
+	for len(parents) > 0 {
+		children := map[string]bool{}
+		for commentID, comment := range m.comments {
+			if parents[comment.GetParentCommentId()] {
+				children[commentID] = true
+			}
+		}
+		for parentID := range parents {
+			delete(m.comments, parentID)
+			count++
+		}
+		parents = children
+	}
+
+	return count, nil
+}
@@ -0,0 +1,408 @@
+package blogserver
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/context"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// memoryStore keeps blogs in a map. Useful for tests and local development
+// without mongodb. IDs are generated the same way as in mongodb so clients
+// do not see the difference
+type memoryStore struct {
+	mu    sync.RWMutex
+	blogs map[string]*blogpb.Blog
+	// revisions are the snapshots of every blog write, oldest first
+	revisions map[string][]*blogpb.Blog
+	comments  map[string]*blogpb.Comment
+	authors   map[string]*blogpb.Author
+	index     *searchIndex
+	events    *eventHub
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		blogs:     make(map[string]*blogpb.Blog),
+		revisions: make(map[string][]*blogpb.Blog),
+		comments:  make(map[string]*blogpb.Comment),
+		authors:   make(map[string]*blogpb.Author),
+		index:     newSearchIndex(),
+		events:    newEventHub(),
+	}
+}
+
+func cloneBlog(blog *blogpb.Blog) *blogpb.Blog {
+	return proto.Clone(blog).(*blogpb.Blog)
+}
+
+func (m *memoryStore) Create(ctx context.Context, blog *blogpb.Blog) (*blogpb.Blog, error) {
+	now := timestamppb.Now()
+	data := &blogpb.Blog{
+		Id:         primitive.NewObjectID().Hex(),
+		AuthorId:   blog.GetAuthorId(),
+		Title:      blog.GetTitle(),
+		Content:    blog.GetContent(),
+		Tags:       normalizeTags(blog.GetTags()),
+		Category:   blog.GetCategory(),
+		Status:     blogpb.Blog_DRAFT,
+		Revision:   1,
+		CreateTime: now,
+		UpdateTime: now,
+		CreatedBy:  writer(ctx),
+		UpdatedBy:  writer(ctx),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.knownAuthor(data.AuthorId); err != nil {
+		return nil, err
+	}
+	m.blogs[data.Id] = data
+	m.written(data, blogpb.WatchBlogsResponse_CREATED)
+
+	return cloneBlog(data), nil
+}
+
+// written updates the search index, keeps the revision snapshot and
+// publishes the event after a blog write. The caller must hold the lock
+func (m *memoryStore) written(data *blogpb.Blog, eventType blogpb.WatchBlogsResponse_EventType) {
+	// deleted blogs are not searchable
+	if data.DeleteTime == nil {
+		m.index.add(data)
+	} else {
+		m.index.remove(data.Id)
+	}
+
+	m.revisions[data.Id] = append(m.revisions[data.Id], cloneBlog(data))
+	m.events.publish(eventType, data)
+}
+
+// purge removes the blog with the search index entry, revisions and comments.
+// The caller must hold the lock
+func (m *memoryStore) purge(data *blogpb.Blog) {
+	delete(m.blogs, data.Id)
+	delete(m.revisions, data.Id)
+	m.index.remove(data.Id)
+	m.deleteBlogComments(data.Id)
+	m.events.publish(blogpb.WatchBlogsResponse_DELETED, data)
+}
+
+func (m *memoryStore) Read(ctx context.Context, id string) (*blogpb.Blog, error) {
+	if _, err := parseObjectID(id); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.blogs[id]
+	if !ok {
+		return nil, withID(errBlogNotFound, id)
+	}
+
+	return cloneBlog(data), nil
+}
+
+// write runs fn on the stored blog if checkWrite allows it, bumps
+// the revision and the update time and publishes the event.
+// fn can refuse the write before changing the blog
+func (m *memoryStore) write(ctx context.Context, id string, expectedRevision int64, deleted bool, eventType blogpb.WatchBlogsResponse_EventType, fn func(data *blogpb.Blog) error) (*blogpb.Blog, error) {
+	if _, err := parseObjectID(id); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.blogs[id]
+	if !ok {
+		return nil, withID(errBlogNotFound, id)
+	}
+	if err := checkWrite(data, expectedRevision, deleted); err != nil {
+		return nil, err
+	}
+
+	if err := fn(data); err != nil {
+		return nil, err
+	}
+	data.Revision++
+	data.UpdateTime = timestamppb.Now()
+	data.UpdatedBy = writer(ctx)
+	m.written(data, eventType)
+
+	return cloneBlog(data), nil
+}
+
+func (m *memoryStore) Update(ctx context.Context, blog *blogpb.Blog, fields []string, expectedRevision int64) (*blogpb.Blog, error) {
+	return m.write(ctx, blog.GetId(), expectedRevision, false, blogpb.WatchBlogsResponse_UPDATED, func(data *blogpb.Blog) error {
+		if containsString(fields, "author_id") {
+			if err := m.knownAuthor(blog.GetAuthorId()); err != nil {
+				return err
+			}
+		}
+		setBlogFields(data, blog, fields)
+		return nil
+	})
+}
+
+func (m *memoryStore) Delete(ctx context.Context, id string, expectedRevision int64) (*blogpb.Blog, error) {
+	return m.write(ctx, id, expectedRevision, false, blogpb.WatchBlogsResponse_DELETED, func(data *blogpb.Blog) error {
+		data.DeleteTime = timestamppb.Now()
+		return nil
+	})
+}
+
+func (m *memoryStore) Undelete(ctx context.Context, id string, expectedRevision int64) (*blogpb.Blog, error) {
+	return m.write(ctx, id, expectedRevision, true, blogpb.WatchBlogsResponse_UPDATED, func(data *blogpb.Blog) error {
+		data.DeleteTime = nil
+		return nil
+	})
+}
+
+func (m *memoryStore) SetStatus(ctx context.Context, id string, status blogpb.Blog_Status, publishTime time.Time, expectedRevision int64) (*blogpb.Blog, error) {
+	return m.write(ctx, id, expectedRevision, false, blogpb.WatchBlogsResponse_UPDATED, func(data *blogpb.Blog) error {
+		if err := checkStatus(data, status); err != nil {
+			return err
+		}
+		setStatus(data, status, publishTime)
+		return nil
+	})
+}
+
+func (m *memoryStore) PublishScheduled(ctx context.Context, now time.Time) (int64, error) {
+	m.mu.RLock()
+	var ids []string
+	for id, data := range m.blogs {
+		if data.Status == blogpb.Blog_SCHEDULED && !data.PublishTime.AsTime().After(now) {
+			ids = append(ids, id)
+		}
+	}
+	m.mu.RUnlock()
+
+	var count int64
+	for _, id := range ids {
+		_, err := m.write(ctx, id, 0, false, blogpb.WatchBlogsResponse_UPDATED, func(data *blogpb.Blog) error {
+			// the blog could be changed after it was found
+			if data.Status != blogpb.Blog_SCHEDULED || data.PublishTime.AsTime().After(now) {
+				return errInvalidStatus
+			}
+			data.Status = blogpb.Blog_PUBLISHED
+			return nil
+		})
+		if err == nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (m *memoryStore) Purge(ctx context.Context, id string, expectedRevision int64) error {
+	if _, err := parseObjectID(id); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.blogs[id]
+	if !ok {
+		return withID(errBlogNotFound, id)
+	}
+	if expectedRevision != 0 && data.Revision != expectedRevision {
+		return withID(errRevisionMismatch, id)
+	}
+	m.purge(data)
+
+	return nil
+}
+
+func (m *memoryStore) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var count int64
+	for _, data := range m.blogs {
+		if data.DeleteTime != nil && data.DeleteTime.AsTime().Before(before) {
+			m.purge(data)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (m *memoryStore) ReadRevision(ctx context.Context, id string, revision int64) (*blogpb.Blog, error) {
+	if _, err := parseObjectID(id); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, ok := m.blogs[id]; !ok {
+		return nil, withID(errBlogNotFound, id)
+	}
+	for _, snapshot := range m.revisions[id] {
+		if snapshot.Revision == revision {
+			return cloneBlog(snapshot), nil
+		}
+	}
+
+	return nil, withID(errRevisionNotFound, id)
+}
+
+func (m *memoryStore) ListRevisions(ctx context.Context, id string, fn func(*blogpb.Blog) error) error {
+	if _, err := parseObjectID(id); err != nil {
+		return err
+	}
+
+	// Snapshots are immutable so they can be used without the lock
+	m.mu.RLock()
+	_, ok := m.blogs[id]
+	snapshots := m.revisions[id]
+	m.mu.RUnlock()
+	if !ok {
+		return withID(errBlogNotFound, id)
+	}
+
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if err := fn(cloneBlog(snapshots[i])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *memoryStore) CreateMany(ctx context.Context, blogs []*blogpb.Blog) ([]*batchResult, error) {
+	results := make([]*batchResult, len(blogs))
+	for i, blog := range blogs {
+		created, err := m.Create(ctx, blog)
+		results[i] = &batchResult{Blog: created, Err: err}
+	}
+
+	return results, nil
+}
+
+func (m *memoryStore) ReadMany(ctx context.Context, ids []string) ([]*batchResult, error) {
+	results := make([]*batchResult, len(ids))
+	for i, id := range ids {
+		blog, err := m.Read(ctx, id)
+		results[i] = &batchResult{Blog: blog, Err: err}
+	}
+
+	return results, nil
+}
+
+func (m *memoryStore) DeleteMany(ctx context.Context, ids []string, purge bool) ([]*batchResult, error) {
+	results := make([]*batchResult, len(ids))
+	for i, id := range ids {
+		if purge {
+			err := m.Purge(ctx, id, 0)
+			results[i] = &batchResult{Blog: &blogpb.Blog{Id: id}, Err: err}
+			continue
+		}
+
+		blog, err := m.Delete(ctx, id, 0)
+		results[i] = &batchResult{Blog: blog, Err: err}
+	}
+
+	return results, nil
+}
+
+func (m *memoryStore) List(ctx context.Context, query *blogQuery, fn func(*blogpb.Blog) error) error {
+	var after *blogpb.Blog
+	if query.After != nil {
+		after = &blogpb.Blog{Id: query.After.ID, Title: query.After.Title}
+	}
+
+	// Copy under the lock so fn can be slow (streaming) without blocking writers
+	m.mu.RLock()
+	blogs := make([]*blogpb.Blog, 0, len(m.blogs))
+	for _, blog := range m.blogs {
+		if !query.matches(blog) {
+			continue
+		}
+		if after != nil && !query.before(after, blog) {
+			continue
+		}
+		blogs = append(blogs, cloneBlog(blog))
+	}
+	m.mu.RUnlock()
+
+	// ObjectIDs start with a timestamp so it is close to the insertion order
+	sort.Slice(blogs, func(i, j int) bool { return query.before(blogs[i], blogs[j]) })
+
+	if query.Limit > 0 && len(blogs) > query.Limit {
+		blogs = blogs[:query.Limit]
+	}
+
+	for _, blog := range blogs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(blog); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *memoryStore) Facets(ctx context.Context, query *blogQuery) (*tagFacets, error) {
+	facets := &tagFacets{
+		Tags:       make(map[string]int64),
+		Categories: make(map[string]int64),
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, blog := range m.blogs {
+		if !query.matches(blog) {
+			continue
+		}
+		for _, tag := range blog.GetTags() {
+			facets.Tags[tag]++
+		}
+		if blog.GetCategory() != "" {
+			facets.Categories[blog.GetCategory()]++
+		}
+	}
+
+	return facets, nil
+}
+
+func (m *memoryStore) Search(ctx context.Context, text string, limit int) ([]*searchHit, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids, scores := m.index.search(searchTerms(text))
+
+	var hits []*searchHit
+	for _, id := range ids {
+		if len(hits) >= limit {
+			break
+		}
+		if blog := m.blogs[id]; blog.Status == blogpb.Blog_PUBLISHED {
+			hits = append(hits, &searchHit{Blog: cloneBlog(blog), Score: scores[id]})
+		}
+	}
+
+	return hits, nil
+}
+
+func (m *memoryStore) Watch(ctx context.Context, resumeToken string, fn func(*blogEvent) error) error {
+	return m.events.watch(ctx, resumeToken, fn)
+}
+
+func (m *memoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *memoryStore) Close(ctx context.Context) error {
+	return nil
+}
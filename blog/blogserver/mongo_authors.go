@@ -0,0 +1,174 @@
+package blogserver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"golang.org/x/net/context"
+)
+
+type authorItem struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	DisplayName string             `bson:"display_name"`
+	Email       string             `bson:"email"`
+	Bio         string             `bson:"bio"`
+	CreateTime  time.Time          `bson:"create_time"`
+	UpdateTime  time.Time          `bson:"update_time"`
+}
+
+func (item *authorItem) toAuthorpb() *blogpb.Author {
+	return &blogpb.Author{
+		Id:          item.ID.Hex(),
+		DisplayName: item.DisplayName,
+		Email:       item.Email,
+		Bio:         item.Bio,
+		CreateTime:  timestampProto(item.CreateTime),
+		UpdateTime:  timestampProto(item.UpdateTime),
+	}
+}
+
+// knownAuthors returns the ids of the authors which exist
+func (m *mongoStore) knownAuthors(ctx context.Context, ids []string) (map[string]bool, error) {
+	objids := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		// invalid ids can not be authors
+		if objid, err := parseObjectID(id); err == nil {
+			objids = append(objids, objid)
+		}
+	}
+
+	known := make(map[string]bool, len(objids))
+	if len(objids) == 0 {
+		return known, nil
+	}
+
+	filter := bson.D{primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "$in", Value: objids}}}}
+	opts := options.Find().SetProjection(bson.D{primitive.E{Key: "_id", Value: 1}})
+	cur, err := m.authors.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		data := &authorItem{}
+		if err := cur.Decode(data); err != nil {
+			return nil, fmt.Errorf("Error decoding authorItem %v", err)
+		}
+		known[data.ID.Hex()] = true
+	}
+
+	return known, cur.Err()
+}
+
+// knownAuthor returns errUnknownAuthor if there is no author with the id
+func (m *mongoStore) knownAuthor(ctx context.Context, id string) error {
+	known, err := m.knownAuthors(ctx, []string{id})
+	if err != nil {
+		return err
+	}
+	if !known[id] {
+		return withID(errUnknownAuthor, id)
+	}
+
+	return nil
+}
+
+func (m *mongoStore) CreateAuthor(ctx context.Context, author *blogpb.Author) (*blogpb.Author, error) {
+	// mongodb keeps milliseconds, truncate to return the stored time
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	data := &authorItem{
+		ID:          primitive.NewObjectID(),
+		DisplayName: author.GetDisplayName(),
+		Email:       author.GetEmail(),
+		Bio:         author.GetBio(),
+		CreateTime:  now,
+		UpdateTime:  now,
+	}
+
+	if _, err := m.authors.InsertOne(ctx, data); err != nil {
+		return nil, err
+	}
+
+	return data.toAuthorpb(), nil
+}
+
+func (m *mongoStore) ReadAuthor(ctx context.Context, id string) (*blogpb.Author, error) {
+	objid, err := parseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &authorItem{}
+	filter := bson.D{primitive.E{Key: "_id", Value: objid}}
+	if err := m.authors.FindOne(ctx, filter).Decode(data); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, withID(errAuthorNotFound, id)
+		}
+		return nil, err
+	}
+
+	return data.toAuthorpb(), nil
+}
+
+func (m *mongoStore) UpdateAuthor(ctx context.Context, author *blogpb.Author, fields []string) (*blogpb.Author, error) {
+	objid, err := parseObjectID(author.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.D{primitive.E{Key: "update_time", Value: time.Now().UTC()}}
+	for _, field := range fields {
+		switch field {
+		case "display_name":
+			set = append(set, primitive.E{Key: "display_name", Value: author.GetDisplayName()})
+		case "email":
+			set = append(set, primitive.E{Key: "email", Value: author.GetEmail()})
+		case "bio":
+			set = append(set, primitive.E{Key: "bio", Value: author.GetBio()})
+		}
+	}
+
+	filter := bson.D{primitive.E{Key: "_id", Value: objid}}
+	update := bson.D{primitive.E{Key: "$set", Value: set}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	data := &authorItem{}
+	if err := m.authors.FindOneAndUpdate(ctx, filter, update, opts).Decode(data); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, withID(errAuthorNotFound, author.GetId())
+		}
+		return nil, err
+	}
+
+	return data.toAuthorpb(), nil
+}
+
+func (m *mongoStore) ListAuthors(ctx context.Context, fn func(*blogpb.Author) error) error {
+	opts := options.Find().SetSort(bson.D{primitive.E{Key: "_id", Value: 1}})
+	cur, err := m.authors.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		author := &authorItem{}
+		if err := cur.Decode(author); err != nil {
+			return fmt.Errorf("Error decoding authorItem %v", err)
+		}
+
+		if err := fn(author.toAuthorpb()); err != nil {
+			return err
+		}
+	}
+
+	return cur.Err()
+}
@@ -0,0 +1,201 @@
+package blogserver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"golang.org/x/net/context"
+)
+
+type commentItem struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty"`
+	BlogID     primitive.ObjectID  `bson:"blog_id"`
+	ParentID   *primitive.ObjectID `bson:"parent_id,omitempty"`
+	AuthorID   string              `bson:"author_id"`
+	Content    string              `bson:"content"`
+	CreateTime time.Time           `bson:"create_time"`
+}
+
+func (item *commentItem) toCommentpb() *blogpb.Comment {
+	comment := &blogpb.Comment{
+		Id:         item.ID.Hex(),
+		BlogId:     item.BlogID.Hex(),
+		AuthorId:   item.AuthorID,
+		Content:    item.Content,
+		CreateTime: timestampProto(item.CreateTime),
+	}
+	if item.ParentID != nil {
+		comment.ParentCommentId = item.ParentID.Hex()
+	}
+
+	return comment
+}
+
+// liveBlog returns errBlogNotFound if the blog is not found or deleted
+func (m *mongoStore) liveBlog(ctx context.Context, blogID primitive.ObjectID) error {
+	filter := bson.D{primitive.E{Key: "_id", Value: blogID}, notDeleted}
+	count, err := m.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return withID(errBlogNotFound, blogID.Hex())
+	}
+
+	return nil
+}
+
+// deleteBlogComments cascades the purge of the blogs
+func (m *mongoStore) deleteBlogComments(ctx context.Context, blogIDs []primitive.ObjectID) error {
+	filter := bson.D{primitive.E{Key: "blog_id", Value: bson.D{primitive.E{Key: "$in", Value: blogIDs}}}}
+	_, err := m.comments.DeleteMany(ctx, filter)
+
+	return err
+}
+
+func (m *mongoStore) CreateComment(ctx context.Context, comment *blogpb.Comment) (*blogpb.Comment, error) {
+	blogID, err := parseObjectID(comment.GetBlogId())
+	if err != nil {
+		return nil, err
+	}
+	if err := m.liveBlog(ctx, blogID); err != nil {
+		return nil, err
+	}
+
+	data := &commentItem{
+		ID:         primitive.NewObjectID(),
+		BlogID:     blogID,
+		AuthorID:   comment.GetAuthorId(),
+		Content:    comment.GetContent(),
+		CreateTime: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	if comment.GetParentCommentId() != "" {
+		parentID, err := parseObjectID(comment.GetParentCommentId())
+		if err != nil {
+			return nil, err
+		}
+
+		filter := bson.D{primitive.E{Key: "_id", Value: parentID}, primitive.E{Key: "blog_id", Value: blogID}}
+		count, err := m.comments.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, withID(errParentNotFound, parentID.Hex())
+		}
+		data.ParentID = &parentID
+	}
+
+	if _, err := m.comments.InsertOne(ctx, data); err != nil {
+		return nil, err
+	}
+
+	return data.toCommentpb(), nil
+}
+
+func (m *mongoStore) ListComments(ctx context.Context, blogID string, fn func(*blogpb.Comment) error) error {
+	objid, err := parseObjectID(blogID)
+	if err != nil {
+		return err
+	}
+	if err := m.liveBlog(ctx, objid); err != nil {
+		return err
+	}
+
+	filter := bson.D{primitive.E{Key: "blog_id", Value: objid}}
+	opts := options.Find().SetSort(bson.D{primitive.E{Key: "_id", Value: 1}})
+
+	cur, err := m.comments.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		comment := &commentItem{}
+		if err := cur.Decode(comment); err != nil {
+			return fmt.Errorf("Error decoding commentItem %v", err)
+		}
+
+		if err := fn(comment.toCommentpb()); err != nil {
+			return err
+		}
+	}
+
+	return cur.Err()
+}
+
+func (m *mongoStore) ReadComment(ctx context.Context, id string) (*blogpb.Comment, error) {
+	objid, err := parseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &commentItem{}
+	res := m.comments.FindOne(ctx, bson.D{primitive.E{Key: "_id", Value: objid}})
+	if err := res.Decode(data); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, withID(errCommentNotFound, id)
+		}
+		return nil, err
+	}
+
+	return data.toCommentpb(), nil
+}
+
+func (m *mongoStore) DeleteComment(ctx context.Context, id string) (int64, error) {
+	objid, err := parseObjectID(id)
+	if err != nil {
+		return 0, err
+	}
+
+	// Collect the replies level by level
+	ids := []primitive.ObjectID{objid}
+	parents := ids
+	for len(parents) > 0 {
+		filter := bson.D{primitive.E{Key: "parent_id", Value: bson.D{primitive.E{Key: "$in", Value: parents}}}}
+		opts := options.Find().SetProjection(bson.D{primitive.E{Key: "_id", Value: 1}})
+
+		cur, err := m.comments.Find(ctx, filter, opts)
+		if err != nil {
+			return 0, err
+		}
+
+		var children []primitive.ObjectID
+		for cur.Next(ctx) {
+			child := &commentItem{}
+			if err := cur.Decode(child); err != nil {
+				cur.Close(ctx)
+				return 0, fmt.Errorf("Error decoding commentItem %v", err)
+			}
+			children = append(children, child.ID)
+		}
+		err = cur.Err()
+		cur.Close(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		ids = append(ids, children...)
+		parents = children
+	}
+
+	filter := bson.D{primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "$in", Value: ids}}}}
+	res, err := m.comments.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	if res.DeletedCount == 0 {
+		return 0, withID(errCommentNotFound, id)
+	}
+
+	return res.DeletedCount, nil
+}
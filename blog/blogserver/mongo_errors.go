@@ -0,0 +1,50 @@
+package blogserver
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+	"golang.org/x/net/context"
+)
+
+// mongoError classifies the mongo driver errors returned by the mongo store
+// as errUnavailable or errStorageTimeout. Context errors and the other
+// errors are returned as they are
+func mongoError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if err == mongo.ErrClientDisconnected {
+		return fmt.Errorf("%w: %v", errUnavailable, err)
+	}
+
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		if cmdErr.IsMaxTimeMSExpiredError() {
+			return fmt.Errorf("%w: %v", errStorageTimeout, err)
+		}
+		// The driver knows the network and the primary change errors
+		drvErr := driver.Error{Code: cmdErr.Code, Message: cmdErr.Message, Labels: cmdErr.Labels, Name: cmdErr.Name}
+		if drvErr.RetryableRead() || drvErr.NodeIsRecovering() || drvErr.NotMaster() {
+			return fmt.Errorf("%w: %v", errUnavailable, err)
+		}
+		return err
+	}
+
+	var connErr topology.ConnectionError
+	if errors.As(err, &connErr) {
+		return fmt.Errorf("%w: %v", errUnavailable, err)
+	}
+
+	// No server was found before the server selection timeout, the
+	// context errors of the selection are returned above
+	var selectionErr topology.ServerSelectionError
+	if errors.As(err, &selectionErr) {
+		return fmt.Errorf("%w: %v", errUnavailable, err)
+	}
+
+	return err
+}
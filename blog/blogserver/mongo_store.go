@@ -0,0 +1,1139 @@
+package blogserver
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"golang.org/x/net/context"
+)
+
+type blogItem struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty"`
+	AuthorID string             `bson:"author_id"`
+	Content  string             `bson:"content"`
+	Title    string             `bson:"title"`
+	Tags     []string           `bson:"tags,omitempty"`
+	Category string             `bson:"category,omitempty"`
+	// Status is missing in blogs written before the statuses
+	Status      blogpb.Blog_Status `bson:"status,omitempty"`
+	PublishTime *time.Time         `bson:"publish_time,omitempty"`
+	Revision    int64              `bson:"revision"`
+	CreateTime  time.Time          `bson:"create_time"`
+	UpdateTime  time.Time          `bson:"update_time"`
+	DeleteTime  *time.Time         `bson:"delete_time,omitempty"`
+	CreatedBy   string             `bson:"created_by,omitempty"`
+	UpdatedBy   string             `bson:"updated_by,omitempty"`
+}
+
+func (item *blogItem) toBlogbp() *blogpb.Blog {
+	blog := &blogpb.Blog{
+		Id:         item.ID.Hex(),
+		AuthorId:   item.AuthorID,
+		Title:      item.Title,
+		Content:    item.Content,
+		Tags:       item.Tags,
+		Category:   item.Category,
+		Status:     item.Status,
+		Revision:   item.Revision,
+		CreateTime: timestampProto(item.CreateTime),
+		UpdateTime: timestampProto(item.UpdateTime),
+		CreatedBy:  item.CreatedBy,
+		UpdatedBy:  item.UpdatedBy,
+	}
+	if blog.Status == blogpb.Blog_STATUS_UNSPECIFIED {
+		// blogs written before the statuses were all published
+		blog.Status = blogpb.Blog_PUBLISHED
+	}
+	if item.PublishTime != nil {
+		blog.PublishTime = timestampProto(*item.PublishTime)
+	}
+	if item.DeleteTime != nil {
+		blog.DeleteTime = timestampProto(*item.DeleteTime)
+	}
+
+	return blog
+}
+
+// newBlogItem converts the blog back to the stored document
+func newBlogItem(blog *blogpb.Blog) (*blogItem, error) {
+	objid, err := parseObjectID(blog.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	item := &blogItem{
+		ID:         objid,
+		AuthorID:   blog.GetAuthorId(),
+		Title:      blog.GetTitle(),
+		Content:    blog.GetContent(),
+		Tags:       blog.GetTags(),
+		Category:   blog.GetCategory(),
+		Status:     blog.GetStatus(),
+		Revision:   blog.GetRevision(),
+		CreateTime: blog.GetCreateTime().AsTime(),
+		UpdateTime: blog.GetUpdateTime().AsTime(),
+		CreatedBy:  blog.GetCreatedBy(),
+		UpdatedBy:  blog.GetUpdatedBy(),
+	}
+	if blog.GetPublishTime() != nil {
+		publishTime := blog.GetPublishTime().AsTime()
+		item.PublishTime = &publishTime
+	}
+	if blog.GetDeleteTime() != nil {
+		deleteTime := blog.GetDeleteTime().AsTime()
+		item.DeleteTime = &deleteTime
+	}
+
+	return item, nil
+}
+
+// revisionItem is the snapshot of a blog revision
+type revisionItem struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	BlogID primitive.ObjectID `bson:"blog_id"`
+	Blog   *blogItem          `bson:"blog"`
+}
+
+// notDeleted matches blogs which are not soft deleted
+var notDeleted = primitive.E{Key: "delete_time", Value: nil}
+
+// statusIn matches blogs with any of the statuses
+func statusIn(statuses []blogpb.Blog_Status) primitive.E {
+	values := bson.A{}
+	for _, status := range statuses {
+		values = append(values, status)
+		if status == blogpb.Blog_PUBLISHED {
+			// blogs without the status are published
+			values = append(values, nil)
+		}
+	}
+
+	return primitive.E{Key: "status", Value: bson.D{primitive.E{Key: "$in", Value: values}}}
+}
+
+// mongoStore keeps blogs in the blog.blog, their revisions in the
+// blog.blog_revision, comments in the blog.comment and authors in the
+// blog.author mongodb collections
+type mongoStore struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+	revisions  *mongo.Collection
+	comments   *mongo.Collection
+	authors    *mongo.Collection
+}
+
+// serverSelectionTimeout fails the requests fast with errUnavailable
+// when mongodb can not be reached
+const serverSelectionTimeout = 5 * time.Second
+
+func newMongoStore(ctx context.Context, cfg MongoConfig) (*mongoStore, error) {
+	opts := options.Client().ApplyURI(cfg.URI).SetServerSelectionTimeout(serverSelectionTimeout)
+	if cfg.Username != "" {
+		opts.SetAuth(options.Credential{Username: cfg.Username, Password: cfg.Password})
+	}
+	client, err := mongo.NewClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	store := &mongoStore{
+		client:     client,
+		collection: client.Database("blog").Collection("blog"),
+		revisions:  client.Database("blog").Collection("blog_revision"),
+		comments:   client.Database("blog").Collection("comment"),
+		authors:    client.Database("blog").Collection("author"),
+	}
+	if err := store.createIndexes(ctx); err != nil {
+		// ctx can be done already when the indexes timed out
+		if derr := client.Disconnect(context.Background()); derr != nil {
+			log.Printf("Error on disconnecting mongodb: %v", derr)
+		}
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// createIndexes makes sure the ListBlog filters and orders and the purge
+// of deleted blogs are indexed and creates the text index used by Search.
+// The tags index is a multikey index over the tags array
+func (m *mongoStore) createIndexes(ctx context.Context) error {
+	textWeights := bson.D{
+		primitive.E{Key: "title", Value: titleWeight},
+		primitive.E{Key: "content", Value: contentWeight},
+	}
+
+	_, err := m.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{primitive.E{Key: "author_id", Value: 1}, primitive.E{Key: "_id", Value: 1}}},
+		{Keys: bson.D{primitive.E{Key: "title", Value: 1}, primitive.E{Key: "_id", Value: 1}}},
+		{Keys: bson.D{primitive.E{Key: "tags", Value: 1}, primitive.E{Key: "_id", Value: 1}}},
+		{Keys: bson.D{primitive.E{Key: "category", Value: 1}, primitive.E{Key: "_id", Value: 1}}},
+		{Keys: bson.D{primitive.E{Key: "delete_time", Value: 1}}},
+		{Keys: bson.D{primitive.E{Key: "status", Value: 1}, primitive.E{Key: "publish_time", Value: 1}}},
+		{
+			Keys:    bson.D{primitive.E{Key: "title", Value: "text"}, primitive.E{Key: "content", Value: "text"}},
+			Options: options.Index().SetName("blog_text").SetWeights(textWeights),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = m.revisions.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{primitive.E{Key: "blog_id", Value: 1}, primitive.E{Key: "blog.revision", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = m.comments.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{primitive.E{Key: "blog_id", Value: 1}, primitive.E{Key: "_id", Value: 1}}},
+		{Keys: bson.D{primitive.E{Key: "parent_id", Value: 1}}},
+	})
+
+	return err
+}
+
+func (m *mongoStore) Create(ctx context.Context, blog *blogpb.Blog) (*blogpb.Blog, error) {
+	if err := m.knownAuthor(ctx, blog.GetAuthorId()); err != nil {
+		return nil, err
+	}
+
+	// mongodb keeps milliseconds, truncate to return the stored time
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	data := blogItem{
+		AuthorID:   blog.GetAuthorId(),
+		Title:      blog.GetTitle(),
+		Content:    blog.GetContent(),
+		Tags:       normalizeTags(blog.GetTags()),
+		Category:   blog.GetCategory(),
+		Status:     blogpb.Blog_DRAFT,
+		Revision:   1,
+		CreateTime: now,
+		UpdateTime: now,
+		CreatedBy:  writer(ctx),
+		UpdatedBy:  writer(ctx),
+	}
+
+	res, err := m.collection.InsertOne(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	objid, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, fmt.Errorf("Can not convert ot objectId: %v", res.InsertedID)
+	}
+	data.ID = objid
+
+	m.saveRevisions(ctx, &data)
+
+	return data.toBlogbp(), nil
+}
+
+// saveRevisions keeps the snapshots of the written blogs. mongodb without
+// a replica set has no transactions so it is done after the write, which
+// is already done then. A failure is only logged and the write succeeds,
+// ReadRevision returns errRevisionNotFound for the missing snapshots
+func (m *mongoStore) saveRevisions(ctx context.Context, blogs ...*blogItem) {
+	if len(blogs) == 0 {
+		return
+	}
+
+	docs := make([]interface{}, len(blogs))
+	for i, blog := range blogs {
+		docs[i] = &revisionItem{BlogID: blog.ID, Blog: blog}
+	}
+
+	if _, err := m.revisions.InsertMany(ctx, docs); err != nil {
+		log.Printf("Error on saving %d blog revisions: %v", len(blogs), err)
+	}
+}
+
+// purged deletes the comments and revisions of the purged blogs
+func (m *mongoStore) purged(ctx context.Context, ids []primitive.ObjectID) error {
+	in := primitive.E{Key: "blog_id", Value: bson.D{primitive.E{Key: "$in", Value: ids}}}
+	if _, err := m.revisions.DeleteMany(ctx, bson.D{in}); err != nil {
+		return err
+	}
+
+	return m.deleteBlogComments(ctx, ids)
+}
+
+func (m *mongoStore) Read(ctx context.Context, id string) (*blogpb.Blog, error) {
+	objid, err := parseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &blogItem{}
+	filter := bson.D{primitive.E{Key: "_id", Value: objid}}
+
+	res := m.collection.FindOne(ctx, filter)
+	if err := res.Decode(data); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, withID(errBlogNotFound, id)
+		}
+		return nil, err
+	}
+
+	return data.toBlogbp(), nil
+}
+
+// revisionFilter matches the blog by id and by revision if expectedRevision is set
+func revisionFilter(objid primitive.ObjectID, expectedRevision int64) bson.D {
+	filter := bson.D{primitive.E{Key: "_id", Value: objid}}
+	if expectedRevision != 0 {
+		filter = append(filter, primitive.E{Key: "revision", Value: expectedRevision})
+	}
+
+	return filter
+}
+
+// missingError tells why a write with revisionFilter did not match any blog
+func (m *mongoStore) missingError(ctx context.Context, objid primitive.ObjectID, expectedRevision int64) error {
+	if expectedRevision == 0 {
+		return withID(errBlogNotFound, objid.Hex())
+	}
+
+	filter := bson.D{primitive.E{Key: "_id", Value: objid}}
+	count, err := m.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return withID(errRevisionMismatch, objid.Hex())
+	}
+
+	return withID(errBlogNotFound, objid.Hex())
+}
+
+// write atomically applies set and unset to the blog if checkWrite allows it,
+// increments the revision and sets the update time
+func (m *mongoStore) write(ctx context.Context, id string, expectedRevision int64, deleted bool, set, unset bson.D) (*blogpb.Blog, error) {
+	return m.writeIf(ctx, id, expectedRevision, deleted, nil, nil, set, unset)
+}
+
+// writeIf is write allowed only for blogs matching cond. check tells
+// why the stored blog does not match cond
+func (m *mongoStore) writeIf(ctx context.Context, id string, expectedRevision int64, deleted bool, cond bson.D, check func(*blogpb.Blog) error, set, unset bson.D) (*blogpb.Blog, error) {
+	objid, err := parseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := append(revisionFilter(objid, expectedRevision), cond...)
+	if deleted {
+		filter = append(filter, primitive.E{Key: "delete_time", Value: bson.D{primitive.E{Key: "$ne", Value: nil}}})
+	} else {
+		filter = append(filter, notDeleted)
+	}
+
+	set = append(set,
+		primitive.E{Key: "update_time", Value: time.Now().UTC()},
+		primitive.E{Key: "updated_by", Value: writer(ctx)},
+	)
+	update := bson.D{
+		primitive.E{Key: "$set", Value: set},
+		primitive.E{Key: "$inc", Value: bson.D{primitive.E{Key: "revision", Value: 1}}},
+	}
+	if len(unset) > 0 {
+		update = append(update, primitive.E{Key: "$unset", Value: unset})
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	data := &blogItem{}
+	res := m.collection.FindOneAndUpdate(ctx, filter, update, opts)
+	if err := res.Decode(data); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, m.writeError(ctx, objid, expectedRevision, deleted, check)
+		}
+		return nil, err
+	}
+
+	m.saveRevisions(ctx, data)
+
+	return data.toBlogbp(), nil
+}
+
+// writeError tells why the write filter did not match any blog
+func (m *mongoStore) writeError(ctx context.Context, objid primitive.ObjectID, expectedRevision int64, deleted bool, check func(*blogpb.Blog) error) error {
+	data := &blogItem{}
+	filter := bson.D{primitive.E{Key: "_id", Value: objid}}
+	if err := m.collection.FindOne(ctx, filter).Decode(data); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return withID(errBlogNotFound, objid.Hex())
+		}
+		return err
+	}
+
+	if err := checkWrite(data.toBlogbp(), expectedRevision, deleted); err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(data.toBlogbp()); err != nil {
+			return err
+		}
+	}
+
+	// The blog was changed between the write and the read
+	return withID(errRevisionMismatch, objid.Hex())
+}
+
+func (m *mongoStore) Update(ctx context.Context, blog *blogpb.Blog, fields []string, expectedRevision int64) (*blogpb.Blog, error) {
+	if containsString(fields, "author_id") {
+		if err := m.knownAuthor(ctx, blog.GetAuthorId()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only the given fields are set so concurrent updates of other fields are kept
+	set := bson.D{}
+	for _, field := range fields {
+		switch field {
+		case "author_id":
+			set = append(set, primitive.E{Key: "author_id", Value: blog.GetAuthorId()})
+		case "title":
+			set = append(set, primitive.E{Key: "title", Value: blog.GetTitle()})
+		case "content":
+			set = append(set, primitive.E{Key: "content", Value: blog.GetContent()})
+		case "tags":
+			set = append(set, primitive.E{Key: "tags", Value: normalizeTags(blog.GetTags())})
+		case "category":
+			set = append(set, primitive.E{Key: "category", Value: blog.GetCategory()})
+		}
+	}
+
+	return m.write(ctx, blog.GetId(), expectedRevision, false, set, nil)
+}
+
+func (m *mongoStore) Delete(ctx context.Context, id string, expectedRevision int64) (*blogpb.Blog, error) {
+	set := bson.D{primitive.E{Key: "delete_time", Value: time.Now().UTC()}}
+
+	return m.write(ctx, id, expectedRevision, false, set, nil)
+}
+
+func (m *mongoStore) Undelete(ctx context.Context, id string, expectedRevision int64) (*blogpb.Blog, error) {
+	unset := bson.D{primitive.E{Key: "delete_time", Value: ""}}
+
+	return m.write(ctx, id, expectedRevision, true, bson.D{}, unset)
+}
+
+func (m *mongoStore) SetStatus(ctx context.Context, id string, status blogpb.Blog_Status, publishTime time.Time, expectedRevision int64) (*blogpb.Blog, error) {
+	set := bson.D{primitive.E{Key: "status", Value: status}}
+	var unset bson.D
+	switch status {
+	case blogpb.Blog_DRAFT:
+		unset = bson.D{primitive.E{Key: "publish_time", Value: ""}}
+	case blogpb.Blog_SCHEDULED, blogpb.Blog_PUBLISHED:
+		set = append(set, primitive.E{Key: "publish_time", Value: publishTime.UTC()})
+	}
+
+	cond := bson.D{statusIn(statusFrom[status])}
+	check := func(blog *blogpb.Blog) error {
+		return checkStatus(blog, status)
+	}
+
+	return m.writeIf(ctx, id, expectedRevision, false, cond, check, set, unset)
+}
+
+func (m *mongoStore) PublishScheduled(ctx context.Context, now time.Time) (int64, error) {
+	due := bson.D{
+		primitive.E{Key: "status", Value: blogpb.Blog_SCHEDULED},
+		primitive.E{Key: "publish_time", Value: bson.D{primitive.E{Key: "$lte", Value: now}}},
+	}
+
+	opts := options.Find().SetProjection(bson.D{primitive.E{Key: "_id", Value: 1}})
+	cur, err := m.collection.Find(ctx, append(due, notDeleted), opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cur.Next(ctx) {
+		data := &blogItem{}
+		if err := cur.Decode(data); err != nil {
+			return 0, fmt.Errorf("Error decoding blogItem %v", err)
+		}
+		ids = append(ids, data.ID)
+	}
+	if err := cur.Err(); err != nil {
+		return 0, err
+	}
+
+	// One write per blog to keep the revision snapshots. The blogs changed
+	// after they were found do not match due anymore
+	var count int64
+	set := bson.D{primitive.E{Key: "status", Value: blogpb.Blog_PUBLISHED}}
+	for _, objid := range ids {
+		_, err := m.writeIf(ctx, objid.Hex(), 0, false, due, nil, set, nil)
+		if err == nil {
+			count++
+			continue
+		}
+		if !errors.Is(err, errRevisionMismatch) && !errors.Is(err, errBlogNotFound) {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+func (m *mongoStore) Purge(ctx context.Context, id string, expectedRevision int64) error {
+	objid, err := parseObjectID(id)
+	if err != nil {
+		return err
+	}
+
+	res, err := m.collection.DeleteOne(ctx, revisionFilter(objid, expectedRevision))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount <= 0 {
+		return m.missingError(ctx, objid, expectedRevision)
+	}
+
+	return m.purged(ctx, []primitive.ObjectID{objid})
+}
+
+func (m *mongoStore) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	filter := bson.D{primitive.E{Key: "delete_time", Value: bson.D{primitive.E{Key: "$lt", Value: before}}}}
+
+	// Find the ids first to delete the comments and revisions of the purged blogs
+	opts := options.Find().SetProjection(bson.D{primitive.E{Key: "_id", Value: 1}})
+	cur, err := m.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cur.Next(ctx) {
+		data := &blogItem{}
+		if err := cur.Decode(data); err != nil {
+			return 0, fmt.Errorf("Error decoding blogItem %v", err)
+		}
+		ids = append(ids, data.ID)
+	}
+	if err := cur.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// A blog undeleted since the Find keeps its comments and revisions
+	in := primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "$in", Value: ids}}}
+	res, err := m.collection.DeleteMany(ctx, append(bson.D{in}, filter...))
+	if err != nil {
+		return 0, err
+	}
+	if res.DeletedCount < int64(len(ids)) {
+		ids, err = m.missingIDs(ctx, ids)
+		if err != nil {
+			return res.DeletedCount, err
+		}
+	}
+
+	return res.DeletedCount, m.purged(ctx, ids)
+}
+
+// missingIDs returns the ids without a blog in the collection
+func (m *mongoStore) missingIDs(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	in := primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "$in", Value: ids}}}
+	opts := options.Find().SetProjection(bson.D{primitive.E{Key: "_id", Value: 1}})
+	cur, err := m.collection.Find(ctx, bson.D{in}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	found := make(map[primitive.ObjectID]bool, len(ids))
+	for cur.Next(ctx) {
+		data := &blogItem{}
+		if err := cur.Decode(data); err != nil {
+			return nil, fmt.Errorf("Error decoding blogItem %v", err)
+		}
+		found[data.ID] = true
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	var missing []primitive.ObjectID
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	return missing, nil
+}
+
+func (m *mongoStore) CreateMany(ctx context.Context, blogs []*blogpb.Blog) ([]*batchResult, error) {
+	authorIDs := make([]string, len(blogs))
+	for i, blog := range blogs {
+		authorIDs[i] = blog.GetAuthorId()
+	}
+	known, err := m.knownAuthors(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	results := make([]*batchResult, len(blogs))
+	items := make([]*blogItem, len(blogs))
+	// docIndex maps the inserted documents to the blogs
+	var docs []interface{}
+	var docIndex []int
+	for i, blog := range blogs {
+		if !known[blog.GetAuthorId()] {
+			results[i] = &batchResult{Err: withID(errUnknownAuthor, blog.GetAuthorId())}
+			continue
+		}
+
+		// IDs are generated here to know them for the results when some inserts fail
+		data := &blogItem{
+			ID:         primitive.NewObjectID(),
+			AuthorID:   blog.GetAuthorId(),
+			Title:      blog.GetTitle(),
+			Content:    blog.GetContent(),
+			Tags:       normalizeTags(blog.GetTags()),
+			Category:   blog.GetCategory(),
+			Status:     blogpb.Blog_DRAFT,
+			Revision:   1,
+			CreateTime: now,
+			UpdateTime: now,
+			CreatedBy:  writer(ctx),
+			UpdatedBy:  writer(ctx),
+		}
+		items[i] = data
+		docs = append(docs, data)
+		docIndex = append(docIndex, i)
+		results[i] = &batchResult{Blog: data.toBlogbp()}
+	}
+	if len(docs) == 0 {
+		return results, nil
+	}
+
+	// Unordered insert continues after a failed blog
+	_, err = m.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if bulkErr, ok := err.(mongo.BulkWriteException); ok && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			i := docIndex[writeErr.Index]
+			results[i] = &batchResult{Err: fmt.Errorf("Error on inserting blog: %v", writeErr.Message)}
+			items[i] = nil
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	created := make([]*blogItem, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			created = append(created, item)
+		}
+	}
+	m.saveRevisions(ctx, created...)
+
+	return results, nil
+}
+
+// findMany returns the blogs matching the filter and the ids by hex id
+func (m *mongoStore) findMany(ctx context.Context, ids []primitive.ObjectID, filter bson.D) (map[string]*blogpb.Blog, error) {
+	filter = append(filter, primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "$in", Value: ids}}})
+
+	cur, err := m.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	blogs := make(map[string]*blogpb.Blog, len(ids))
+	for cur.Next(ctx) {
+		data := &blogItem{}
+		if err := cur.Decode(data); err != nil {
+			return nil, fmt.Errorf("Error decoding blogItem %v", err)
+		}
+		blogs[data.ID.Hex()] = data.toBlogbp()
+	}
+
+	return blogs, cur.Err()
+}
+
+// parseObjectIDs parses the valid ids. Invalid ones get an error result
+func parseObjectIDs(ids []string) ([]primitive.ObjectID, []*batchResult) {
+	results := make([]*batchResult, len(ids))
+	objids := make([]primitive.ObjectID, 0, len(ids))
+	for i, id := range ids {
+		objid, err := parseObjectID(id)
+		if err != nil {
+			results[i] = &batchResult{Err: err}
+			continue
+		}
+		objids = append(objids, objid)
+	}
+
+	return objids, results
+}
+
+// fillResults sets the results of the ids not failed yet from the found blogs.
+// Not found blogs get errBlogNotFound
+func fillResults(ids []string, results []*batchResult, blogs map[string]*blogpb.Blog) []*batchResult {
+	for i, id := range ids {
+		if results[i] != nil {
+			continue
+		}
+		if blog, ok := blogs[id]; ok {
+			results[i] = &batchResult{Blog: blog}
+		} else {
+			results[i] = &batchResult{Err: withID(errBlogNotFound, id)}
+		}
+	}
+
+	return results
+}
+
+func (m *mongoStore) ReadMany(ctx context.Context, ids []string) ([]*batchResult, error) {
+	objids, results := parseObjectIDs(ids)
+
+	blogs, err := m.findMany(ctx, objids, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+
+	return fillResults(ids, results, blogs), nil
+}
+
+func (m *mongoStore) DeleteMany(ctx context.Context, ids []string, purge bool) ([]*batchResult, error) {
+	objids, results := parseObjectIDs(ids)
+
+	filter := bson.D{notDeleted}
+	if purge {
+		filter = bson.D{}
+	}
+
+	// Find the blogs first to report the missing ones
+	found, err := m.findMany(ctx, objids, filter)
+	if err != nil {
+		return nil, err
+	}
+	foundIDs := make([]primitive.ObjectID, 0, len(found))
+	for _, objid := range objids {
+		if _, ok := found[objid.Hex()]; ok {
+			foundIDs = append(foundIDs, objid)
+		}
+	}
+	if len(foundIDs) == 0 {
+		return fillResults(ids, results, found), nil
+	}
+
+	in := primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "$in", Value: foundIDs}}}
+	if purge {
+		if _, err := m.collection.DeleteMany(ctx, bson.D{in}); err != nil {
+			return nil, err
+		}
+		if err := m.purged(ctx, foundIDs); err != nil {
+			return nil, err
+		}
+		for id := range found {
+			found[id] = &blogpb.Blog{Id: id}
+		}
+		return fillResults(ids, results, found), nil
+	}
+
+	now := time.Now().UTC()
+	update := bson.D{
+		primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "delete_time", Value: now},
+			primitive.E{Key: "update_time", Value: now},
+			primitive.E{Key: "updated_by", Value: writer(ctx)},
+		}},
+		primitive.E{Key: "$inc", Value: bson.D{primitive.E{Key: "revision", Value: 1}}},
+	}
+	if _, err := m.collection.UpdateMany(ctx, bson.D{in, notDeleted}, update); err != nil {
+		return nil, err
+	}
+
+	// Blogs deleted concurrently by another write are not matched
+	deleteTime := primitive.E{Key: "delete_time", Value: now}
+	deleted, err := m.findMany(ctx, foundIDs, bson.D{deleteTime})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*blogItem, 0, len(deleted))
+	for _, blog := range deleted {
+		item, err := newBlogItem(blog)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	m.saveRevisions(ctx, items...)
+
+	return fillResults(ids, results, deleted), nil
+}
+
+func (m *mongoStore) ReadRevision(ctx context.Context, id string, revision int64) (*blogpb.Blog, error) {
+	objid, err := parseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &revisionItem{}
+	filter := bson.D{
+		primitive.E{Key: "blog_id", Value: objid},
+		primitive.E{Key: "blog.revision", Value: revision},
+	}
+	if err := m.revisions.FindOne(ctx, filter).Decode(data); err != nil {
+		if err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		if err := m.missingError(ctx, objid, 0); err != nil {
+			return nil, err
+		}
+		return nil, withID(errRevisionNotFound, id)
+	}
+
+	return data.Blog.toBlogbp(), nil
+}
+
+func (m *mongoStore) ListRevisions(ctx context.Context, id string, fn func(*blogpb.Blog) error) error {
+	objid, err := parseObjectID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := m.missingError(ctx, objid, 0); err != nil {
+		return err
+	}
+
+	filter := bson.D{primitive.E{Key: "blog_id", Value: objid}}
+	opts := options.Find().SetSort(bson.D{primitive.E{Key: "blog.revision", Value: -1}})
+	cur, err := m.revisions.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		data := &revisionItem{}
+		if err := cur.Decode(data); err != nil {
+			return fmt.Errorf("Error decoding revisionItem %v", err)
+		}
+
+		if err := fn(data.Blog.toBlogbp()); err != nil {
+			return err
+		}
+	}
+
+	return cur.Err()
+}
+
+// listFilter builds the find filter and sort for the query
+func listFilter(query *blogQuery) (bson.D, bson.D, error) {
+	filter := bson.D{}
+	if !query.ShowDeleted {
+		filter = append(filter, notDeleted)
+	}
+	if query.AuthorID != "" {
+		filter = append(filter, primitive.E{Key: "author_id", Value: query.AuthorID})
+	}
+	if len(query.Statuses) > 0 {
+		filter = append(filter, statusIn(query.Statuses))
+	}
+	if query.Category != "" {
+		filter = append(filter, primitive.E{Key: "category", Value: query.Category})
+	}
+	if len(query.Tags) > 0 {
+		op := "$in"
+		if query.AllTags {
+			op = "$all"
+		}
+		filter = append(filter, primitive.E{Key: "tags", Value: bson.D{primitive.E{Key: op, Value: query.Tags}}})
+	}
+	if query.TitlePrefix != "" {
+		filter = append(filter, primitive.E{Key: "title", Value: primitive.Regex{
+			Pattern: "^" + regexp.QuoteMeta(query.TitlePrefix),
+		}})
+	}
+
+	direction, cmp := 1, "$gt"
+	if query.descending() {
+		direction, cmp = -1, "$lt"
+	}
+
+	sort := bson.D{primitive.E{Key: "_id", Value: direction}}
+	if query.byTitle() {
+		sort = bson.D{
+			primitive.E{Key: "title", Value: direction},
+			primitive.E{Key: "_id", Value: direction},
+		}
+	}
+
+	if query.After != nil {
+		afterID, err := parseObjectID(query.After.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		after := bson.D{primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: cmp, Value: afterID}}}}
+		if query.byTitle() {
+			after = bson.D{primitive.E{Key: "$or", Value: bson.A{
+				bson.D{primitive.E{Key: "title", Value: bson.D{primitive.E{Key: cmp, Value: query.After.Title}}}},
+				bson.D{
+					primitive.E{Key: "title", Value: query.After.Title},
+					primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: cmp, Value: afterID}}},
+				},
+			}}}
+		}
+		filter = append(filter, after...)
+	}
+
+	return filter, sort, nil
+}
+
+func (m *mongoStore) List(ctx context.Context, query *blogQuery, fn func(*blogpb.Blog) error) error {
+	filter, sort, err := listFilter(query)
+	if err != nil {
+		return err
+	}
+
+	opts := options.Find().SetSort(sort)
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+
+	cur, err := m.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		blog := &blogItem{}
+		if err := cur.Decode(blog); err != nil {
+			return fmt.Errorf("Error decoding blogItem %v", err)
+		}
+
+		if err := fn(blog.toBlogbp()); err != nil {
+			return err
+		}
+	}
+
+	return cur.Err()
+}
+
+// facetCount is a count of the Facets aggregation
+type facetCount struct {
+	Value string `bson:"_id"`
+	Count int64  `bson:"count"`
+}
+
+func (m *mongoStore) Facets(ctx context.Context, query *blogQuery) (*tagFacets, error) {
+	filter, _, err := listFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	groupBy := func(field string) bson.D {
+		return bson.D{primitive.E{Key: "$group", Value: bson.D{
+			primitive.E{Key: "_id", Value: "$" + field},
+			primitive.E{Key: "count", Value: bson.D{primitive.E{Key: "$sum", Value: 1}}},
+		}}}
+	}
+	hasCategory := bson.D{primitive.E{Key: "category", Value: bson.D{primitive.E{Key: "$gt", Value: ""}}}}
+
+	// One pass over the matching blogs for both counts
+	pipeline := mongo.Pipeline{
+		{primitive.E{Key: "$match", Value: filter}},
+		{primitive.E{Key: "$facet", Value: bson.D{
+			primitive.E{Key: "tags", Value: bson.A{
+				bson.D{primitive.E{Key: "$unwind", Value: "$tags"}},
+				groupBy("tags"),
+			}},
+			primitive.E{Key: "categories", Value: bson.A{
+				bson.D{primitive.E{Key: "$match", Value: hasCategory}},
+				groupBy("category"),
+			}},
+		}}},
+	}
+
+	cur, err := m.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	facets := &tagFacets{
+		Tags:       make(map[string]int64),
+		Categories: make(map[string]int64),
+	}
+	for cur.Next(ctx) {
+		result := &struct {
+			Tags       []facetCount `bson:"tags"`
+			Categories []facetCount `bson:"categories"`
+		}{}
+		if err := cur.Decode(result); err != nil {
+			return nil, fmt.Errorf("Error decoding facets %v", err)
+		}
+
+		for _, c := range result.Tags {
+			facets.Tags[c.Value] = c.Count
+		}
+		for _, c := range result.Categories {
+			facets.Categories[c.Value] = c.Count
+		}
+	}
+
+	return facets, cur.Err()
+}
+
+func (m *mongoStore) Search(ctx context.Context, text string, limit int) ([]*searchHit, error) {
+	// Only the words like the memory store, $search would take the quoted
+	// phrases and the - negations as operators
+	words := strings.Join(searchTerms(text), " ")
+	if words == "" {
+		return nil, nil
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "$text", Value: bson.D{primitive.E{Key: "$search", Value: words}}},
+		notDeleted,
+		statusIn([]blogpb.Blog_Status{blogpb.Blog_PUBLISHED}),
+	}
+	score := bson.D{primitive.E{Key: "score", Value: bson.D{primitive.E{Key: "$meta", Value: "textScore"}}}}
+	opts := options.Find().SetProjection(score).SetSort(score).SetLimit(int64(limit))
+
+	cur, err := m.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var hits []*searchHit
+	for cur.Next(ctx) {
+		item := &struct {
+			blogItem `bson:",inline"`
+			Score    float64 `bson:"score"`
+		}{}
+		if err := cur.Decode(item); err != nil {
+			return nil, fmt.Errorf("Error decoding blogItem %v", err)
+		}
+
+		hits = append(hits, &searchHit{Blog: item.toBlogbp(), Score: item.Score})
+	}
+
+	return hits, cur.Err()
+}
+
+// changeEvent is a mongodb change stream document
+type changeEvent struct {
+	OperationType string    `bson:"operationType"`
+	FullDocument  *blogItem `bson:"fullDocument"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	UpdateDescription struct {
+		UpdatedFields bson.M `bson:"updatedFields"`
+	} `bson:"updateDescription"`
+}
+
+// blogEvent converts the change, returns nil for changes not sent to watchers
+func (c *changeEvent) blogEvent() *blogEvent {
+	event := &blogEvent{Blog: &blogpb.Blog{Id: c.DocumentKey.ID.Hex()}}
+	if c.FullDocument != nil {
+		event.Blog = c.FullDocument.toBlogbp()
+	}
+
+	switch c.OperationType {
+	case "insert":
+		event.Type = blogpb.WatchBlogsResponse_CREATED
+	case "update", "replace":
+		event.Type = blogpb.WatchBlogsResponse_UPDATED
+		if _, ok := c.UpdateDescription.UpdatedFields["delete_time"]; ok {
+			event.Type = blogpb.WatchBlogsResponse_DELETED
+		}
+	case "delete":
+		event.Type = blogpb.WatchBlogsResponse_DELETED
+	default:
+		return nil
+	}
+
+	return event
+}
+
+// changeStreamHistoryLost is the mongodb error code for resume tokens
+// which are not in the oplog anymore
+const changeStreamHistoryLost = 286
+
+// Watch uses mongodb change streams. They work only with replica sets
+func (m *mongoStore) Watch(ctx context.Context, resumeToken string, fn func(*blogEvent) error) error {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if resumeToken != "" {
+		token, err := base64.RawURLEncoding.DecodeString(resumeToken)
+		if err != nil || bson.Raw(token).Validate() != nil {
+			return errInvalidResumeToken
+		}
+		opts.SetResumeAfter(bson.Raw(token))
+	}
+
+	cs, err := m.collection.Watch(ctx, mongo.Pipeline{}, opts)
+	if err != nil {
+		if cmdErr, ok := err.(mongo.CommandError); ok && cmdErr.Code == changeStreamHistoryLost {
+			return fmt.Errorf("%w: %v", errResumeTokenExpired, err)
+		}
+		return err
+	}
+	defer cs.Close(context.Background())
+
+	for cs.Next(ctx) {
+		change := &changeEvent{}
+		if err := cs.Decode(change); err != nil {
+			return fmt.Errorf("Error decoding change event %v", err)
+		}
+
+		event := change.blogEvent()
+		if event == nil {
+			continue
+		}
+		event.Token = base64.RawURLEncoding.EncodeToString(cs.ResumeToken())
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return cs.Err()
+}
+
+func (m *mongoStore) Ping(ctx context.Context) error {
+	return m.client.Ping(ctx, readpref.Primary())
+}
+
+func (m *mongoStore) Close(ctx context.Context) error {
+	return m.client.Disconnect(ctx)
+}
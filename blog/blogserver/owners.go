@@ -0,0 +1,130 @@
+package blogserver
+
+import (
+	"errors"
+
+	"github.com/KestutisKazlauskas/grpc-go/authz"
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+)
+
+// ownedMethods are the BlogService writes of a single blog, the author
+// of the blog is its owner for the policy
+var ownedMethods = []string{
+	"/blog.BlogService/CreateBlog",
+	"/blog.BlogService/UpdateBlog",
+	"/blog.BlogService/DeleteBlog",
+	"/blog.BlogService/UndeleteBlog",
+	"/blog.BlogService/RollbackBlog",
+	"/blog.BlogService/PublishBlog",
+	"/blog.BlogService/UnpublishBlog",
+}
+
+// ownedCommentMethods are the CommentService writes of a single comment,
+// the author of the comment is its owner for the policy
+var ownedCommentMethods = []string{
+	"/blog.CommentService/CreateComment",
+	"/blog.CommentService/DeleteComment",
+}
+
+// ownedAuthorMethods are the AuthorService writes of a single author, the
+// author is its own owner for the policy
+var ownedAuthorMethods = []string{
+	"/blog.AuthorService/UpdateAuthor",
+}
+
+// Owners returns how the authorization policy finds the author_id of the
+// blog, the comment or the author a request is about
+func (s *Service) Owners() map[string]authz.OwnerFunc {
+	owners := make(map[string]authz.OwnerFunc, len(ownedMethods)+len(ownedCommentMethods)+len(ownedAuthorMethods))
+	for _, method := range ownedMethods {
+		owners[method] = s.blogOwner
+	}
+	for _, method := range ownedCommentMethods {
+		owners[method] = s.commentOwner
+	}
+	for _, method := range ownedAuthorMethods {
+		owners[method] = authorOwner
+	}
+
+	return owners
+}
+
+// blogOwner is the author of a new blog or of the stored blog. A write
+// giving the blog to another author has no owner, so only the rules
+// without owner (like an admin role) allow it
+func (s *Service) blogOwner(ctx context.Context, req interface{}) (string, error) {
+	var id, newOwner string
+	switch req := req.(type) {
+	case *blogpb.CreateBlogRequest:
+		return req.GetBlog().GetAuthorId(), nil
+	case *blogpb.UpdateBlogRequest:
+		id = req.GetBlog().GetId()
+		fields, err := updateFields(req.GetUpdateMask(), updatableFields)
+		if err != nil {
+			// UpdateBlog rejects the mask anyway
+			return "", nil
+		}
+		if containsString(fields, "author_id") {
+			newOwner = req.GetBlog().GetAuthorId()
+		}
+	case *blogpb.RollbackBlogRequest:
+		id = req.GetBlogId()
+		old, err := s.store.ReadRevision(ctx, id, req.GetRevision())
+		if errors.Is(err, errRevisionNotFound) || errors.Is(err, errInvalidID) {
+			return "", nil
+		}
+		if err != nil {
+			return "", storeError(err, "Cannot read the blog owner")
+		}
+		newOwner = old.GetAuthorId()
+	case interface{ GetBlogId() string }:
+		id = req.GetBlogId()
+	default:
+		return "", nil
+	}
+
+	blog, err := s.store.Read(ctx, id)
+	if errors.Is(err, errBlogNotFound) || errors.Is(err, errInvalidID) {
+		return "", nil
+	}
+	if err != nil {
+		return "", storeError(err, "Cannot read the blog owner")
+	}
+	if newOwner != "" && newOwner != blog.GetAuthorId() {
+		return "", nil
+	}
+
+	return blog.GetAuthorId(), nil
+}
+
+// commentOwner is the author of a new comment or of the stored comment
+func (s *Service) commentOwner(ctx context.Context, req interface{}) (string, error) {
+	if req, ok := req.(*blogpb.CreateCommentRequest); ok {
+		return req.GetComment().GetAuthorId(), nil
+	}
+	r, ok := req.(interface{ GetCommentId() string })
+	if !ok {
+		return "", nil
+	}
+
+	comment, err := s.store.ReadComment(ctx, r.GetCommentId())
+	if errors.Is(err, errCommentNotFound) || errors.Is(err, errInvalidID) {
+		return "", nil
+	}
+	if err != nil {
+		return "", storeError(err, "Cannot read the comment owner")
+	}
+
+	return comment.GetAuthorId(), nil
+}
+
+// authorOwner is the author updated by the request
+func authorOwner(ctx context.Context, req interface{}) (string, error) {
+	if req, ok := req.(*blogpb.UpdateAuthorRequest); ok {
+		return req.GetAuthor().GetId(), nil
+	}
+
+	return "", nil
+}
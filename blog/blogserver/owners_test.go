@@ -0,0 +1,51 @@
+package blogserver
+
+import (
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+)
+
+func TestOwners(t *testing.T) {
+	s, author := newTestServer(t)
+	store := s.store.(Store)
+	ctx := context.Background()
+	blog := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "title", Content: "content"})
+	comment, err := store.CreateComment(ctx, &blogpb.Comment{BlogId: blog.GetId(), AuthorId: "commenter", Content: "comment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	owners := (&Service{store: store}).Owners()
+
+	tests := []struct {
+		name   string
+		method string
+		req    interface{}
+		want   string
+	}{
+		{"new blog", "/blog.BlogService/CreateBlog", &blogpb.CreateBlogRequest{Blog: &blogpb.Blog{AuthorId: "someone"}}, "someone"},
+		{"stored blog", "/blog.BlogService/DeleteBlog", &blogpb.DeleteBlogRequest{BlogId: blog.GetId()}, author},
+		{"unknown blog", "/blog.BlogService/DeleteBlog", &blogpb.DeleteBlogRequest{BlogId: "unknown"}, ""},
+		{"new comment", "/blog.CommentService/CreateComment", &blogpb.CreateCommentRequest{Comment: &blogpb.Comment{AuthorId: "someone"}}, "someone"},
+		{"stored comment", "/blog.CommentService/DeleteComment", &blogpb.DeleteCommentRequest{CommentId: comment.GetId()}, "commenter"},
+		{"author", "/blog.AuthorService/UpdateAuthor", &blogpb.UpdateAuthorRequest{Author: &blogpb.Author{Id: author}}, author},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ownerOf, ok := owners[tt.method]
+			if !ok {
+				t.Fatalf("no owner for %s", tt.method)
+			}
+			got, err := ownerOf(ctx, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("owner = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
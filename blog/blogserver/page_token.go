@@ -0,0 +1,123 @@
+package blogserver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+var (
+	errInvalidPageToken = errors.New("invalid page token")
+	errInvalidPageSize  = errors.New("page_size can not be negative")
+)
+
+// publishedOnly is the default status filter of the blog lists
+var publishedOnly = []blogpb.Blog_Status{blogpb.Blog_PUBLISHED}
+
+// pageToken is the decoded ListBlog page_token. The request filters are kept
+// in the token so it can not be used with a different query
+type pageToken struct {
+	Cursor      blogCursor                   `json:"cursor"`
+	AuthorID    string                       `json:"author_id,omitempty"`
+	TitlePrefix string                       `json:"title_prefix,omitempty"`
+	Tags        []string                     `json:"tags,omitempty"`
+	AllTags     bool                         `json:"all_tags,omitempty"`
+	Category    string                       `json:"category,omitempty"`
+	Statuses    []blogpb.Blog_Status         `json:"statuses,omitempty"`
+	Order       blogpb.ListBlogRequest_Order `json:"order,omitempty"`
+	ShowDeleted bool                         `json:"show_deleted,omitempty"`
+}
+
+func encodePageToken(query *blogQuery, last *blogpb.Blog) string {
+	token := pageToken{
+		Cursor:      blogCursor{ID: last.GetId()},
+		AuthorID:    query.AuthorID,
+		TitlePrefix: query.TitlePrefix,
+		Tags:        query.Tags,
+		AllTags:     query.AllTags,
+		Category:    query.Category,
+		Statuses:    query.Statuses,
+		Order:       query.Order,
+		ShowDeleted: query.ShowDeleted,
+	}
+	if query.byTitle() {
+		token.Cursor.Title = last.GetTitle()
+	}
+
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// listQuery converts the ListBlog request to the store query
+func listQuery(req *blogpb.ListBlogRequest) (*blogQuery, error) {
+	query := &blogQuery{
+		AuthorID:    req.GetAuthorId(),
+		TitlePrefix: req.GetTitlePrefix(),
+		Tags:        normalizeTags(req.GetTags()),
+		AllTags:     req.GetTagMatch() == blogpb.ListBlogRequest_TAGS_ALL,
+		Category:    req.GetCategory(),
+		Statuses:    req.GetStatuses(),
+		Order:       req.GetOrder(),
+		ShowDeleted: req.GetShowDeleted(),
+		Limit:       int(req.GetPageSize()),
+	}
+
+	if len(query.Statuses) == 0 {
+		query.Statuses = publishedOnly
+	}
+
+	switch {
+	case query.Limit < 0:
+		return nil, errInvalidPageSize
+	case query.Limit == 0:
+		query.Limit = defaultPageSize
+	case query.Limit > maxPageSize:
+		query.Limit = maxPageSize
+	}
+
+	if req.GetPageToken() == "" {
+		return query, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(req.GetPageToken())
+	if err != nil {
+		return nil, errInvalidPageToken
+	}
+	token := &pageToken{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, errInvalidPageToken
+	}
+	sameTags := equalStrings(token.Tags, query.Tags) && token.AllTags == query.AllTags
+	sameStatuses := fmt.Sprint(token.Statuses) == fmt.Sprint(query.Statuses)
+	if token.AuthorID != query.AuthorID || token.TitlePrefix != query.TitlePrefix || !sameTags || token.Category != query.Category || !sameStatuses ||
+		token.Order != query.Order || token.ShowDeleted != query.ShowDeleted {
+		return nil, errors.New("page_token does not match the request")
+	}
+	if _, err := parseObjectID(token.Cursor.ID); err != nil {
+		return nil, errInvalidPageToken
+	}
+	query.After = &token.Cursor
+
+	return query, nil
+}
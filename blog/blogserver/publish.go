@@ -0,0 +1,64 @@
+package blogserver
+
+import (
+	"log"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+)
+
+// publishScheduled publishes the scheduled blogs which are due every
+// interval until ctx is done
+func publishScheduled(ctx context.Context, store BlogStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := store.PublishScheduled(ctx, time.Now())
+		if err != nil {
+			log.Printf("Error on publishing scheduled blogs: %v", err)
+		} else if count > 0 {
+			log.Printf("Published %d scheduled blogs", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *server) PublishBlog(ctx context.Context, req *blogpb.PublishBlogRequest) (*blogpb.PublishBlogResponse, error) {
+	status, publishTime := blogpb.Blog_PUBLISHED, time.Now()
+	if req.GetPublishTime() != nil && req.GetPublishTime().AsTime().After(publishTime) {
+		status, publishTime = blogpb.Blog_SCHEDULED, req.GetPublishTime().AsTime()
+	}
+
+	blog, err := s.store.SetStatus(ctx, req.GetBlogId(), status, publishTime, req.GetExpectedRevision())
+	if err != nil {
+		return nil, storeError(err, "Cannot publish blog")
+	}
+
+	return &blogpb.PublishBlogResponse{
+		Blog: blog,
+	}, nil
+}
+
+func (s *server) UnpublishBlog(ctx context.Context, req *blogpb.UnpublishBlogRequest) (*blogpb.UnpublishBlogResponse, error) {
+	status := blogpb.Blog_DRAFT
+	if req.GetArchive() {
+		status = blogpb.Blog_ARCHIVED
+	}
+
+	blog, err := s.store.SetStatus(ctx, req.GetBlogId(), status, time.Time{}, req.GetExpectedRevision())
+	if err != nil {
+		return nil, storeError(err, "Cannot unpublish blog")
+	}
+
+	return &blogpb.UnpublishBlogResponse{
+		Blog: blog,
+	}, nil
+}
@@ -0,0 +1,178 @@
+package blogserver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCheckStatus(t *testing.T) {
+	const (
+		draft     = blogpb.Blog_DRAFT
+		scheduled = blogpb.Blog_SCHEDULED
+		published = blogpb.Blog_PUBLISHED
+		archived  = blogpb.Blog_ARCHIVED
+	)
+
+	tests := []struct {
+		from, to blogpb.Blog_Status
+		ok       bool
+	}{
+		{draft, draft, false},
+		{draft, scheduled, true},
+		{draft, published, true},
+		{draft, archived, true},
+		{scheduled, draft, true},
+		{scheduled, scheduled, true},
+		{scheduled, published, true},
+		{scheduled, archived, true},
+		{published, draft, true},
+		{published, scheduled, false},
+		{published, published, false},
+		{published, archived, true},
+		{archived, draft, false},
+		{archived, scheduled, true},
+		{archived, published, true},
+		{archived, archived, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from.String()+" to "+tt.to.String(), func(t *testing.T) {
+			err := checkStatus(&blogpb.Blog{Status: tt.from}, tt.to)
+			if ok := err == nil; ok != tt.ok {
+				t.Errorf("checkStatus() = %v, want ok %v", err, tt.ok)
+			}
+			if err != nil && !errors.Is(err, errInvalidStatus) {
+				t.Errorf("checkStatus() = %v, want errInvalidStatus", err)
+			}
+		})
+	}
+}
+
+func TestPublishBlog(t *testing.T) {
+	s, author := newTestServer(t)
+	ctx := context.Background()
+	blog := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "title", Content: "content"})
+	id := blog.GetId()
+	later := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+
+	res, err := s.PublishBlog(ctx, &blogpb.PublishBlogRequest{BlogId: id, PublishTime: timestamppb.New(later)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.GetBlog(); got.GetStatus() != blogpb.Blog_SCHEDULED || !got.GetPublishTime().AsTime().Equal(later) {
+		t.Errorf("PublishBlog() later = %v %v, want SCHEDULED at %v", got.GetStatus(), got.GetPublishTime().AsTime(), later)
+	}
+
+	// a past publish time publishes now
+	res, err = s.PublishBlog(ctx, &blogpb.PublishBlogRequest{BlogId: id, PublishTime: timestamppb.New(time.Now().Add(-time.Hour))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := res.GetBlog().GetPublishTime().AsTime()
+	if res.GetBlog().GetStatus() != blogpb.Blog_PUBLISHED || time.Since(published) > time.Minute {
+		t.Errorf("PublishBlog() = %v at %v, want PUBLISHED now", res.GetBlog().GetStatus(), published)
+	}
+
+	// the archive keeps the publish time
+	archived, err := s.UnpublishBlog(ctx, &blogpb.UnpublishBlogRequest{BlogId: id, Archive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := archived.GetBlog(); got.GetStatus() != blogpb.Blog_ARCHIVED || !got.GetPublishTime().AsTime().Equal(published) {
+		t.Errorf("UnpublishBlog() archive = %v %v, want ARCHIVED at %v", got.GetStatus(), got.GetPublishTime(), published)
+	}
+	_, err = s.UnpublishBlog(ctx, &blogpb.UnpublishBlogRequest{BlogId: id, Archive: true})
+	wantReason(t, err, codes.FailedPrecondition, "INVALID_STATUS_CHANGE")
+
+	// an archived blog is published again before it is a draft
+	_, err = s.UnpublishBlog(ctx, &blogpb.UnpublishBlogRequest{BlogId: id})
+	wantReason(t, err, codes.FailedPrecondition, "INVALID_STATUS_CHANGE")
+	if _, err := s.PublishBlog(ctx, &blogpb.PublishBlogRequest{BlogId: id}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the draft has no publish time
+	draft, err := s.UnpublishBlog(ctx, &blogpb.UnpublishBlogRequest{BlogId: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := draft.GetBlog(); got.GetStatus() != blogpb.Blog_DRAFT || got.GetPublishTime() != nil {
+		t.Errorf("UnpublishBlog() = %v %v, want DRAFT without publish time", got.GetStatus(), got.GetPublishTime())
+	}
+}
+
+func TestPublishScheduled(t *testing.T) {
+	s, author := newTestServer(t)
+	store := s.store.(Store)
+	ctx := context.Background()
+	now := time.Now()
+
+	schedule := func(title string, at time.Time) string {
+		t.Helper()
+		blog := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: title, Content: "content"})
+		if _, err := s.PublishBlog(ctx, &blogpb.PublishBlogRequest{BlogId: blog.GetId(), PublishTime: timestamppb.New(at)}); err != nil {
+			t.Fatal(err)
+		}
+		return blog.GetId()
+	}
+	soon := schedule("soon", now.Add(time.Hour))
+	later := schedule("later", now.Add(2*time.Hour))
+
+	count, err := store.PublishScheduled(ctx, now.Add(90*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("PublishScheduled() = %d, want 1", count)
+	}
+	for id, want := range map[string]blogpb.Blog_Status{soon: blogpb.Blog_PUBLISHED, later: blogpb.Blog_SCHEDULED} {
+		blog, err := store.Read(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if blog.GetStatus() != want {
+			t.Errorf("blog %q = %v, want %v", blog.GetTitle(), blog.GetStatus(), want)
+		}
+	}
+}
+
+func TestPublishScheduledJob(t *testing.T) {
+	s, author := newTestServer(t)
+	store := s.store.(Store)
+	blog := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "title", Content: "content"})
+	at := time.Now().Add(50 * time.Millisecond)
+	if _, err := s.PublishBlog(context.Background(), &blogpb.PublishBlogRequest{BlogId: blog.GetId(), PublishTime: timestamppb.New(at)}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		publishScheduled(ctx, store, 10*time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		got, err := store.Read(context.Background(), blog.GetId())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.GetStatus() == blogpb.Blog_PUBLISHED {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("the scheduled blog was not published by the job")
+}
@@ -0,0 +1,30 @@
+package blogserver
+
+import (
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// purgeDeleted permanently removes blogs soft deleted longer than retention
+// every interval until ctx is done
+func purgeDeleted(ctx context.Context, store BlogStore, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := store.PurgeDeleted(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Error on purging deleted blogs: %v", err)
+		} else if count > 0 {
+			log.Printf("Purged %d deleted blogs", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
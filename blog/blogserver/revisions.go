@@ -0,0 +1,78 @@
+package blogserver
+
+import (
+	"fmt"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+)
+
+func (s *server) ListBlogRevisions(req *blogpb.ListBlogRevisionsRequest, stream blogpb.BlogService_ListBlogRevisionsServer) error {
+	err := s.store.ListRevisions(stream.Context(), req.GetBlogId(), func(blog *blogpb.Blog) error {
+		return stream.Send(&blogpb.ListBlogRevisionsResponse{Blog: blog})
+	})
+	if err != nil {
+		return storeError(err, "Error on reading blog revisions")
+	}
+
+	return nil
+}
+
+func (s *server) GetBlogRevision(ctx context.Context, req *blogpb.GetBlogRevisionRequest) (*blogpb.GetBlogRevisionResponse, error) {
+	blog, err := s.store.ReadRevision(ctx, req.GetBlogId(), req.GetRevision())
+	if err != nil {
+		return nil, storeError(err, "Cannot read blog revision")
+	}
+
+	return &blogpb.GetBlogRevisionResponse{
+		Blog: blog,
+	}, nil
+}
+
+func (s *server) RollbackBlog(ctx context.Context, req *blogpb.RollbackBlogRequest) (*blogpb.RollbackBlogResponse, error) {
+	old, err := s.store.ReadRevision(ctx, req.GetBlogId(), req.GetRevision())
+	if err != nil {
+		return nil, storeError(err, "Cannot read blog revision")
+	}
+
+	// The rollback is a normal update so it gets a new revision and can be
+	// rolled back too
+	blog, err := s.store.Update(ctx, old, updatableFields, req.GetExpectedRevision())
+	if err != nil {
+		return nil, storeError(err, "Cannot rollback blog")
+	}
+
+	return &blogpb.RollbackBlogResponse{
+		Blog: blog,
+	}, nil
+}
+
+func (s *server) DiffBlogRevisions(ctx context.Context, req *blogpb.DiffBlogRevisionsRequest) (*blogpb.DiffBlogRevisionsResponse, error) {
+	from, err := s.store.ReadRevision(ctx, req.GetBlogId(), req.GetFromRevision())
+	if err != nil {
+		return nil, storeError(err, "Cannot read blog revision")
+	}
+	to, err := s.store.ReadRevision(ctx, req.GetBlogId(), req.GetToRevision())
+	if err != nil {
+		return nil, storeError(err, "Cannot read blog revision")
+	}
+
+	diff, err := unifiedDiff(
+		fmt.Sprintf("revision %d", from.GetRevision()),
+		fmt.Sprintf("revision %d", to.GetRevision()),
+		revisionLines(from),
+		revisionLines(to),
+	)
+	if err != nil {
+		return nil, rpcerror.New(codes.ResourceExhausted, "DIFF_TOO_BIG", fmt.Sprintf("Cannot diff blog revisions, %v", err)).
+			WithResource("blog", req.GetBlogId()).
+			Err()
+	}
+
+	return &blogpb.DiffBlogRevisionsResponse{
+		Diff: diff,
+	}, nil
+}
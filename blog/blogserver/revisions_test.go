@@ -0,0 +1,76 @@
+package blogserver
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestRevisions(t *testing.T) {
+	s, author := newTestServer(t)
+	ctx := context.Background()
+	blog := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "first", Content: "line 1\nline 2"})
+	for i := 2; i <= 3; i++ {
+		_, err := s.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{
+			Blog:             &blogpb.Blog{Id: blog.GetId(), Content: fmt.Sprintf("line 1\nline %d", i)},
+			UpdateMask:       &fieldmaskpb.FieldMask{Paths: []string{"content"}},
+			ExpectedRevision: int64(i - 1),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rev, err := s.GetBlogRevision(ctx, &blogpb.GetBlogRevisionRequest{BlogId: blog.GetId(), Revision: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.GetBlog().GetRevision() != 2 || rev.GetBlog().GetContent() != "line 1\nline 2" {
+		t.Errorf("revision 2 = %v", rev.GetBlog())
+	}
+	_, err = s.GetBlogRevision(ctx, &blogpb.GetBlogRevisionRequest{BlogId: blog.GetId(), Revision: 4})
+	wantReason(t, err, codes.NotFound, "REVISION_NOT_FOUND")
+
+	diff, err := s.DiffBlogRevisions(ctx, &blogpb.DiffBlogRevisionsRequest{BlogId: blog.GetId(), FromRevision: 1, ToRevision: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff.GetDiff(), "--- revision 1\n+++ revision 3\n") || !strings.Contains(diff.GetDiff(), "-line 2\n+line 3\n") {
+		t.Errorf("diff =\n%s", diff.GetDiff())
+	}
+
+	// the rollback is a new revision with the old fields
+	rollback, err := s.RollbackBlog(ctx, &blogpb.RollbackBlogRequest{BlogId: blog.GetId(), Revision: 1, ExpectedRevision: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rollback.GetBlog(); got.GetRevision() != 4 || got.GetContent() != "line 1\nline 2" {
+		t.Errorf("RollbackBlog() = %v, want revision 4 with the content of revision 1", got)
+	}
+	diff, err = s.DiffBlogRevisions(ctx, &blogpb.DiffBlogRevisionsRequest{BlogId: blog.GetId(), FromRevision: 1, ToRevision: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.GetDiff() != "" {
+		t.Errorf("diff of revision 1 and its rollback =\n%s\nwant empty", diff.GetDiff())
+	}
+
+	var revisions []int64
+	err = s.store.ListRevisions(ctx, blog.GetId(), func(blog *blogpb.Blog) error {
+		revisions = append(revisions, blog.GetRevision())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(revisions, []int64{4, 3, 2, 1}) {
+		t.Errorf("ListRevisions() = %v, want newest first [4 3 2 1]", revisions)
+	}
+}
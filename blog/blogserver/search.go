@@ -0,0 +1,222 @@
+package blogserver
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+)
+
+const (
+	defaultSearchSize = 20
+	maxSearchSize     = 100
+
+	// title words count more than content words, same weights as the mongodb text index
+	titleWeight   = 2
+	contentWeight = 1
+
+	// words around a match shown in a content snippet
+	snippetRadius = 6
+	maxSnippets   = 3
+)
+
+// searchHit is one blog found by BlogStore.Search
+type searchHit struct {
+	Blog  *blogpb.Blog
+	Score float64
+}
+
+// token is a word of a text with its byte position
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits the text into lower case words
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			tokens = append(tokens, token{term: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+
+	return tokens
+}
+
+// searchTerms returns the unique words of the search query
+func searchTerms(query string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, t := range tokenize(query) {
+		if !seen[t.term] {
+			seen[t.term] = true
+			terms = append(terms, t.term)
+		}
+	}
+
+	return terms
+}
+
+// searchIndex is an inverted index used by stores without native text search.
+// It is not safe for concurrent use, the owning store must lock it
+type searchIndex struct {
+	// postings maps a word to the blog ids and the weighted word count
+	postings map[string]map[string]float64
+	// terms keeps the indexed words of a blog to remove them on update
+	terms map[string][]string
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings: make(map[string]map[string]float64),
+		terms:    make(map[string][]string),
+	}
+}
+
+// add indexes the blog replacing the previous version if any
+func (idx *searchIndex) add(blog *blogpb.Blog) {
+	idx.remove(blog.GetId())
+
+	counts := map[string]float64{}
+	for _, t := range tokenize(blog.GetTitle()) {
+		counts[t.term] += titleWeight
+	}
+	for _, t := range tokenize(blog.GetContent()) {
+		counts[t.term] += contentWeight
+	}
+
+	terms := make([]string, 0, len(counts))
+	for term, count := range counts {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[string]float64)
+		}
+		idx.postings[term][blog.GetId()] = count
+		terms = append(terms, term)
+	}
+	idx.terms[blog.GetId()] = terms
+}
+
+func (idx *searchIndex) remove(id string) {
+	for _, term := range idx.terms[id] {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.terms, id)
+}
+
+// search returns the ids of blogs matching any of the terms with tf-idf scores,
+// best first
+func (idx *searchIndex) search(terms []string) ([]string, map[string]float64) {
+	total := float64(len(idx.terms))
+	scores := map[string]float64{}
+	for _, term := range terms {
+		docs := idx.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+		idf := math.Log(1 + total/float64(len(docs)))
+		for id, count := range docs {
+			scores[id] += count * idf
+		}
+	}
+
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+
+	return ids, scores
+}
+
+// highlight wraps the matched tokens of text[from:to] in <em></em>
+func highlight(text string, tokens []token, matched map[string]bool, from, to int) string {
+	var b strings.Builder
+	pos := from
+	for _, t := range tokens {
+		if t.start < from || t.end > to || !matched[t.term] {
+			continue
+		}
+		b.WriteString(text[pos:t.start])
+		b.WriteString("<em>")
+		b.WriteString(text[t.start:t.end])
+		b.WriteString("</em>")
+		pos = t.end
+	}
+	b.WriteString(text[pos:to])
+
+	return b.String()
+}
+
+// snippets returns the highlighted title and content fragments around the
+// matched words of the blog
+func snippets(blog *blogpb.Blog, terms []string) []string {
+	matched := map[string]bool{}
+	for _, term := range terms {
+		matched[term] = true
+	}
+
+	var result []string
+
+	title := blog.GetTitle()
+	titleTokens := tokenize(title)
+	for _, t := range titleTokens {
+		if matched[t.term] {
+			result = append(result, highlight(title, titleTokens, matched, 0, len(title)))
+			break
+		}
+	}
+
+	content := blog.GetContent()
+	tokens := tokenize(content)
+	next, count := 0, 0
+	for i, t := range tokens {
+		if count >= maxSnippets {
+			break
+		}
+		if i < next || !matched[t.term] {
+			continue
+		}
+
+		first := i - snippetRadius
+		if first < next {
+			first = next
+		}
+		last := i + snippetRadius
+		if last >= len(tokens) {
+			last = len(tokens) - 1
+		}
+		next = last + 1
+
+		snippet := highlight(content, tokens, matched, tokens[first].start, tokens[last].end)
+		if first > 0 {
+			snippet = "..." + snippet
+		}
+		if last < len(tokens)-1 {
+			snippet += "..."
+		}
+		result = append(result, snippet)
+		count++
+	}
+
+	return result
+}
@@ -0,0 +1,144 @@
+package blogserver
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []token
+	}{
+		{"", nil},
+		{"  ,. ", nil},
+		{"Hello, gRPC!", []token{{"hello", 0, 5}, {"grpc", 7, 11}}},
+		{"go1.14 rocks", []token{{"go1", 0, 3}, {"14", 4, 6}, {"rocks", 7, 12}}},
+		{"Ąžuolas ėjo", []token{{"ąžuolas", 0, 9}, {"ėjo", 10, 14}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	got := searchTerms("Go go GRPC, go")
+	if want := []string{"go", "grpc"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("searchTerms() = %v, want %v", got, want)
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	idx := newSearchIndex()
+	idx.add(&blogpb.Blog{Id: "a", Title: "grpc", Content: "streams"})
+	idx.add(&blogpb.Blog{Id: "b", Title: "streams", Content: "grpc grpc grpc"})
+	idx.add(&blogpb.Blog{Id: "c", Title: "mongo", Content: "grpc"})
+
+	// b has the most matches, a the title match worth two content words
+	ids, scores := idx.search([]string{"grpc"})
+	if want := []string{"b", "a", "c"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("search() = %v, want %v", ids, want)
+	}
+	if scores["b"] <= scores["a"] || scores["a"] <= scores["c"] {
+		t.Errorf("scores = %v, want b > a > c", scores)
+	}
+
+	// the rarer word is worth more
+	ids, _ = idx.search([]string{"grpc", "mongo"})
+	if ids[0] != "c" {
+		t.Errorf("search() = %v, want c first", ids)
+	}
+
+	// an update replaces the words of the blog
+	idx.add(&blogpb.Blog{Id: "c", Title: "mongo"})
+	ids, _ = idx.search([]string{"grpc"})
+	if want := []string{"b", "a"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("search() after update = %v, want %v", ids, want)
+	}
+
+	idx.remove("c")
+	if ids, _ = idx.search([]string{"mongo"}); len(ids) != 0 {
+		t.Errorf("search() after remove = %v, want none", ids)
+	}
+	if _, ok := idx.postings["mongo"]; ok {
+		t.Error("postings of a removed word are kept")
+	}
+}
+
+// words returns "w0 w1 ..." with the matched words at the positions
+func words(n int, matches ...int) string {
+	w := make([]string, n)
+	for i := range w {
+		w[i] = fmt.Sprintf("w%d", i)
+	}
+	for _, i := range matches {
+		w[i] = "grpc"
+	}
+
+	return strings.Join(w, " ")
+}
+
+func TestSnippets(t *testing.T) {
+	tests := []struct {
+		name string
+		blog *blogpb.Blog
+		want []string
+	}{
+		{
+			"no match",
+			&blogpb.Blog{Title: "title", Content: "content"},
+			nil,
+		},
+		{
+			"title",
+			&blogpb.Blog{Title: "Hello gRPC world", Content: "content"},
+			[]string{"Hello <em>gRPC</em> world"},
+		},
+		{
+			"short content",
+			&blogpb.Blog{Content: "gRPC, is fun"},
+			[]string{"<em>gRPC</em>, is fun"},
+		},
+		{
+			"words around",
+			&blogpb.Blog{Content: words(20, 10)},
+			[]string{"...w4 w5 w6 w7 w8 w9 <em>grpc</em> w11 w12 w13 w14 w15 w16..."},
+		},
+		{
+			"close matches in one snippet",
+			&blogpb.Blog{Content: words(10, 2, 4)},
+			[]string{"w0 w1 <em>grpc</em> w3 <em>grpc</em> w5 w6 w7 w8..."},
+		},
+		{
+			"next snippet after the previous",
+			&blogpb.Blog{Content: words(12, 0, 10)},
+			[]string{"<em>grpc</em> w1 w2 w3 w4 w5 w6...", "...w7 w8 w9 <em>grpc</em> w11"},
+		},
+		{
+			"max snippets",
+			&blogpb.Blog{Content: words(80, 10, 30, 50, 70)},
+			[]string{
+				"...w4 w5 w6 w7 w8 w9 <em>grpc</em> w11 w12 w13 w14 w15 w16...",
+				"...w24 w25 w26 w27 w28 w29 <em>grpc</em> w31 w32 w33 w34 w35 w36...",
+				"...w44 w45 w46 w47 w48 w49 <em>grpc</em> w51 w52 w53 w54 w55 w56...",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippets(tt.blog, []string{"grpc"}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("snippets() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package blogserver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+type server struct {
+	store   BlogStore
+	authors AuthorStore
+	// stopping ends the WatchBlogs streams, nil never ends them
+	stopping context.Context
+}
+
+func (s *server) CreateBlog(ctx context.Context, req *blogpb.CreateBlogRequest) (*blogpb.CreateBlogResponse, error) {
+	blog, err := s.store.Create(ctx, req.GetBlog())
+	if err != nil {
+		return nil, storeError(err, "Internal error")
+	}
+
+	return &blogpb.CreateBlogResponse{
+		Blog: blog,
+	}, nil
+}
+
+func (s *server) ReadBlog(ctx context.Context, req *blogpb.ReadBlogRequest) (*blogpb.ReadBlogResponse, error) {
+	blog, err := s.store.Read(ctx, req.GetBlogId())
+	if err != nil {
+		return nil, storeError(err, "Cannot read blog")
+	}
+
+	return &blogpb.ReadBlogResponse{
+		Blog: blog,
+	}, nil
+}
+
+// updateFields returns the fields listed in the update mask.
+// Empty mask means all the updatable fields
+func updateFields(mask *fieldmaskpb.FieldMask, updatable []string) ([]string, error) {
+	paths := mask.GetPaths()
+	if len(paths) == 0 {
+		return updatable, nil
+	}
+
+	fields := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !containsString(updatable, path) {
+			return nil, fmt.Errorf("unknown update_mask path %q, use one of %v", path, updatable)
+		}
+		fields = append(fields, path)
+	}
+
+	return fields, nil
+}
+
+func (s *server) UpdateBlog(ctx context.Context, req *blogpb.UpdateBlogRequest) (*blogpb.UpdateBlogResponse, error) {
+	fields, err := updateFields(req.GetUpdateMask(), updatableFields)
+	if err != nil {
+		return nil, badRequest("INVALID_UPDATE_MASK", "Invalid update mask", "update_mask", err)
+	}
+
+	blog, err := s.store.Update(ctx, req.GetBlog(), fields, req.GetExpectedRevision())
+	if err != nil {
+		return nil, storeError(err, "Cannot update blog")
+	}
+
+	return &blogpb.UpdateBlogResponse{
+		Blog: blog,
+	}, nil
+
+}
+
+func (s *server) DeleteBlog(ctx context.Context, req *blogpb.DeleteBlogRequest) (*blogpb.DeleteBlogResponse, error) {
+	blogID := req.GetBlogId()
+
+	var err error
+	if req.GetPurge() {
+		err = s.store.Purge(ctx, blogID, req.GetExpectedRevision())
+	} else {
+		_, err = s.store.Delete(ctx, blogID, req.GetExpectedRevision())
+	}
+	if err != nil {
+		return nil, storeError(err, "Error on deleting document")
+	}
+
+	return &blogpb.DeleteBlogResponse{
+		BlogId: blogID,
+	}, nil
+
+}
+
+func (s *server) UndeleteBlog(ctx context.Context, req *blogpb.UndeleteBlogRequest) (*blogpb.UndeleteBlogResponse, error) {
+	blog, err := s.store.Undelete(ctx, req.GetBlogId(), req.GetExpectedRevision())
+	if err != nil {
+		return nil, storeError(err, "Error on undeleting document")
+	}
+
+	return &blogpb.UndeleteBlogResponse{
+		Blog: blog,
+	}, nil
+}
+
+func (s *server) ListBlog(req *blogpb.ListBlogRequest, stream blogpb.BlogService_ListBlogServer) error {
+	return s.listBlogs(stream.Context(), req, stream.Send)
+}
+
+func (s *server) ListBlogsByAuthor(req *blogpb.ListBlogsByAuthorRequest, stream blogpb.BlogService_ListBlogsByAuthorServer) error {
+	if _, err := s.authors.ReadAuthor(stream.Context(), req.GetAuthorId()); err != nil {
+		return storeError(err, "Cannot read author")
+	}
+
+	return s.listBlogs(stream.Context(), &blogpb.ListBlogRequest{
+		PageSize:  req.GetPageSize(),
+		PageToken: req.GetPageToken(),
+		AuthorId:  req.GetAuthorId(),
+		Order:     req.GetOrder(),
+	}, stream.Send)
+}
+
+// listBlogs sends one page of the blogs matching the request
+func (s *server) listBlogs(ctx context.Context, req *blogpb.ListBlogRequest, send func(*blogpb.ListBlogResponse) error) error {
+	query, err := listQuery(req)
+	if err != nil {
+		field := "page_token"
+		if errors.Is(err, errInvalidPageSize) {
+			field = "page_size"
+		}
+		return badRequest("INVALID_LIST_REQUEST", "Invalid list request", field, err)
+	}
+
+	// Ask for one more blog to know if there is a next page.
+	// The previous blog is held back until we know if it is the last one
+	pageSize := query.Limit
+	query.Limit++
+
+	var prev *blogpb.Blog
+	count := 0
+	err = s.store.List(ctx, query, func(blog *blogpb.Blog) error {
+		count++
+		if prev != nil {
+			res := &blogpb.ListBlogResponse{Blog: prev}
+			if count > pageSize {
+				res.NextPageToken = encodePageToken(query, prev)
+			}
+			if err := send(res); err != nil {
+				return err
+			}
+		}
+		prev = blog
+		return nil
+	})
+	if err != nil {
+		return storeError(err, "Error on reading a blog list")
+	}
+
+	if prev != nil && count <= pageSize {
+		return send(&blogpb.ListBlogResponse{Blog: prev})
+	}
+
+	return nil
+}
+
+func (s *server) SearchBlogs(ctx context.Context, req *blogpb.SearchBlogsRequest) (*blogpb.SearchBlogsResponse, error) {
+	terms := searchTerms(req.GetQuery())
+	if len(terms) == 0 {
+		return nil, badRequest("EMPTY_SEARCH_QUERY", "Search query has no words", "query", fmt.Errorf("%q has no words", req.GetQuery()))
+	}
+
+	limit := int(req.GetPageSize())
+	switch {
+	case limit <= 0:
+		limit = defaultSearchSize
+	case limit > maxSearchSize:
+		limit = maxSearchSize
+	}
+
+	hits, err := s.store.Search(ctx, req.GetQuery(), limit)
+	if err != nil {
+		return nil, storeError(err, "Error on searching blogs")
+	}
+
+	res := &blogpb.SearchBlogsResponse{}
+	for _, hit := range hits {
+		res.Results = append(res.Results, &blogpb.SearchResult{
+			Blog:     hit.Blog,
+			Score:    hit.Score,
+			Snippets: snippets(hit.Blog, terms),
+		})
+	}
+
+	return res, nil
+}
+
+func (s *server) WatchBlogs(req *blogpb.WatchBlogsRequest, stream blogpb.BlogService_WatchBlogsServer) error {
+	ctx := stream.Context()
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if s.stopping != nil {
+		go func() {
+			select {
+			case <-s.stopping.Done():
+				cancel()
+			case <-watchCtx.Done():
+			}
+		}()
+	}
+
+	err := s.store.Watch(watchCtx, req.GetResumeToken(), func(event *blogEvent) error {
+		return stream.Send(&blogpb.WatchBlogsResponse{
+			Type:        event.Type,
+			Blog:        event.Blog,
+			ResumeToken: event.Token,
+		})
+	})
+	if ctx.Err() != nil {
+		// the client is gone
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if s.stopping != nil && s.stopping.Err() != nil {
+		return rpcerror.New(codes.Unavailable, "SERVER_STOPPING", "Server is stopping, resume with the last token on another server").
+			WithRetryDelay(storageRetryDelay).
+			Err()
+	}
+	if err != nil {
+		return storeError(err, "Error on watching blogs")
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package blogserver
+
+import (
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestServer is a BlogService server on the memory store with an author
+func newTestServer(t *testing.T) (*server, string) {
+	t.Helper()
+	store := newMemoryStore()
+	author, err := store.CreateAuthor(context.Background(), &blogpb.Author{DisplayName: "Test author"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &server{store: store, authors: store}, author.GetId()
+}
+
+func createTestBlog(t *testing.T, s *server, blog *blogpb.Blog) *blogpb.Blog {
+	t.Helper()
+	res, err := s.CreateBlog(context.Background(), &blogpb.CreateBlogRequest{Blog: blog})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.GetBlog()
+}
+
+func wantReason(t *testing.T, err error, code codes.Code, reason string) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Fatalf("code = %v, want %v (%v)", status.Code(err), code, err)
+	}
+	details, _ := rpcerror.FromError(err)
+	if details.Reason() != reason {
+		t.Errorf("reason = %q, want %q", details.Reason(), reason)
+	}
+}
@@ -0,0 +1,79 @@
+// Package blogserver implements the BlogService, CommentService and
+// AuthorService over a mongodb or in memory store
+package blogserver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+)
+
+// Service is the blog services with their store and background jobs
+type Service struct {
+	store          Store
+	healthInterval time.Duration
+	jobs           context.Context
+	stopJobs       context.CancelFunc
+}
+
+// newStore creates the Store selected by the store setting
+func newStore(ctx context.Context, cfg Config) (Store, error) {
+	switch cfg.Store {
+	case "mongo":
+		fmt.Println("Connecting mongodb")
+		return newMongoStore(ctx, cfg.Mongo)
+	case "memory":
+		fmt.Println("Using in memory store")
+		return newMemoryStore(), nil
+	}
+
+	return nil, fmt.Errorf("unknown store %q, use mongo or memory", cfg.Store)
+}
+
+// New opens the store and starts the purge and publish jobs
+func New(cfg Config) (*Service, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Mongo.ConnectTimeout)
+	defer cancel()
+	store, err := newStore(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	go purgeDeleted(jobsCtx, store, cfg.DeletedRetention, cfg.PurgeInterval)
+	go publishScheduled(jobsCtx, store, cfg.PublishInterval)
+
+	return &Service{
+		store:          store,
+		healthInterval: cfg.HealthInterval,
+		jobs:           jobsCtx,
+		stopJobs:       stopJobs,
+	}, nil
+}
+
+// Register adds the blog, comment and author services to the server. The
+// WatchBlogs streams end when stopping is done, pass the Context of the
+// grpcserver so they do not hold up a graceful stop
+func (s *Service) Register(stopping context.Context, gs *grpc.Server) {
+	blogpb.RegisterBlogServiceServer(gs, &server{store: s.store, authors: s.store, stopping: stopping})
+	blogpb.RegisterCommentServiceServer(gs, &commentServer{store: s.store})
+	blogpb.RegisterAuthorServiceServer(gs, &authorServer{store: s.store})
+}
+
+// WatchHealth reports the services to the health server as NOT_SERVING
+// while the store ping fails, until the service is closed
+func (s *Service) WatchHealth(hs *health.Server) {
+	go watchHealth(s.jobs, s.store, hs, s.healthInterval)
+}
+
+// Close stops the jobs and closes the store
+func (s *Service) Close(ctx context.Context) error {
+	s.stopJobs()
+
+	return s.store.Close(ctx)
+}
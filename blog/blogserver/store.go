@@ -0,0 +1,471 @@
+package blogserver
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+	"github.com/KestutisKazlauskas/grpc-go/identity"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/context"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	// errBlogNotFound is returned by a store when no blog matches the given id
+	errBlogNotFound = errors.New("blog not found")
+	// errInvalidID is returned by a store when the id can not be parsed
+	errInvalidID = errors.New("invalid id")
+	// errRevisionMismatch is returned by a store when the stored blog has
+	// a different revision than expected by the write
+	errRevisionMismatch = errors.New("blog revision does not match")
+	// errBlogNotDeleted is returned by Undelete for a blog which is not deleted
+	errBlogNotDeleted = errors.New("blog is not deleted")
+	// errRevisionNotFound is returned by ReadRevision when there is no snapshot
+	// of the revision
+	errRevisionNotFound = errors.New("blog revision not found")
+	// errCommentNotFound is returned by a store when no comment matches the given id
+	errCommentNotFound = errors.New("comment not found")
+	// errParentNotFound is returned by CreateComment when the parent comment
+	// is not a comment of the same blog
+	errParentNotFound = errors.New("parent comment not found in the blog")
+	// errAuthorNotFound is returned by a store when no author matches the given id
+	errAuthorNotFound = errors.New("author not found")
+	// errUnknownAuthor is returned by blog writes when the author_id is not
+	// the id of an author
+	errUnknownAuthor = errors.New("unknown author")
+	// errInvalidStatus is returned by SetStatus when the blog can not move
+	// from its status to the new one
+	errInvalidStatus = errors.New("blog status can not be changed")
+	// errUnavailable is returned by a store when the storage can not be
+	// reached. The request can be retried
+	errUnavailable = errors.New("storage unavailable")
+	// errStorageTimeout is returned by a store when the storage did not
+	// finish the operation in time
+	errStorageTimeout = errors.New("storage timeout")
+)
+
+// idError is a store error about the object with the id
+type idError struct {
+	err error
+	id  string
+}
+
+// withID tells which blog, comment or author the store error is about
+func withID(err error, id string) error {
+	return &idError{err: err, id: id}
+}
+
+func (e *idError) Error() string {
+	return fmt.Sprintf("%v %q", e.err, e.id)
+}
+
+func (e *idError) Unwrap() error {
+	return e.err
+}
+
+// errorID returns the id of the object the error is about, empty if unknown
+func errorID(err error) string {
+	var idErr *idError
+	if errors.As(err, &idErr) {
+		return idErr.id
+	}
+
+	return ""
+}
+
+// Store is the storage of the blog_server services
+type Store interface {
+	BlogStore
+	CommentStore
+	AuthorStore
+}
+
+// BlogStore is the storage used by the BlogService server.
+// Implementations must be safe for concurrent use. Creating a blog or
+// setting its author_id returns errUnknownAuthor if the author is not
+// in the AuthorStore
+type BlogStore interface {
+	// Create stores a new DRAFT blog and returns it with the generated ID,
+	// revision 1 and the create and update times. Keeps the revision snapshot
+	Create(ctx context.Context, blog *blogpb.Blog) (*blogpb.Blog, error)
+
+	// Read returns errBlogNotFound if there is no blog with the id.
+	// Soft deleted blogs are returned too
+	Read(ctx context.Context, id string) (*blogpb.Blog, error)
+
+	// All the writes below increment the revision, set the update time and
+	// keep a snapshot of the written revision (see ReadRevision). A snapshot
+	// which can not be saved does not fail the write, see saveRevisions.
+	// They return errBlogNotFound if there is no blog with the id and
+	// errRevisionMismatch if expectedRevision is not 0 and the stored blog
+	// has another revision
+
+	// Update sets the fields (see updatableFields) of the blog with the same ID.
+	// Soft deleted blogs are not found
+	Update(ctx context.Context, blog *blogpb.Blog, fields []string, expectedRevision int64) (*blogpb.Blog, error)
+
+	// Delete soft deletes the blog by setting the delete time.
+	// Soft deleted blogs are not found
+	Delete(ctx context.Context, id string, expectedRevision int64) (*blogpb.Blog, error)
+
+	// Undelete clears the delete time.
+	// Returns errBlogNotDeleted if the blog is not soft deleted
+	Undelete(ctx context.Context, id string, expectedRevision int64) (*blogpb.Blog, error)
+
+	// SetStatus moves the blog to the status if statusFrom allows it, returns
+	// errInvalidStatus otherwise. The publish time is set to publishTime for
+	// SCHEDULED and PUBLISHED, cleared for DRAFT and kept for ARCHIVED.
+	// Soft deleted blogs are not found
+	SetStatus(ctx context.Context, id string, status blogpb.Blog_Status, publishTime time.Time, expectedRevision int64) (*blogpb.Blog, error)
+
+	// PublishScheduled publishes the SCHEDULED blogs with the publish time
+	// not after now. Returns the number of published blogs
+	PublishScheduled(ctx context.Context, now time.Time) (int64, error)
+
+	// Purge permanently removes the blog with its revisions, soft deleted or not
+	Purge(ctx context.Context, id string, expectedRevision int64) error
+
+	// PurgeDeleted permanently removes blogs soft deleted before the time.
+	// Returns the number of removed blogs
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+
+	// CreateMany stores the blogs like Create. The results are in the
+	// blogs order
+	CreateMany(ctx context.Context, blogs []*blogpb.Blog) ([]*batchResult, error)
+
+	// ReadMany reads the blogs like Read. The results are in the ids order
+	ReadMany(ctx context.Context, ids []string) ([]*batchResult, error)
+
+	// DeleteMany soft deletes (or purges if purge is set) the blogs like Delete
+	// and Purge without revision checks. The results are in the ids order.
+	// Results of purged blogs have only the ID
+	DeleteMany(ctx context.Context, ids []string, purge bool) ([]*batchResult, error)
+
+	// ReadRevision returns the snapshot of the blog written with the revision.
+	// Returns errRevisionNotFound if there is no such snapshot
+	ReadRevision(ctx context.Context, id string, revision int64) (*blogpb.Blog, error)
+
+	// ListRevisions calls fn for every snapshot of the blog newest first.
+	// Returns errBlogNotFound if there is no blog with the id
+	ListRevisions(ctx context.Context, id string, fn func(*blogpb.Blog) error) error
+
+	// List calls fn for every blog matching the query in query order.
+	// Stops on the first fn error
+	List(ctx context.Context, query *blogQuery, fn func(*blogpb.Blog) error) error
+
+	// Facets counts the blogs matching the query filters per tag and category.
+	// Blogs without a category are not counted in the categories
+	Facets(ctx context.Context, query *blogQuery) (*tagFacets, error)
+
+	// Search returns up to limit not deleted published blogs matching any of
+	// the words of text (see searchTerms), best match first. The text has no
+	// operators, phrases and negations are searched as words
+	Search(ctx context.Context, text string, limit int) ([]*searchHit, error)
+
+	// Watch calls fn for every blog change after the resume token until ctx
+	// is done or fn fails. Empty token watches from now.
+	// Returns errInvalidResumeToken, errResumeTokenExpired or errWatcherTooSlow
+	Watch(ctx context.Context, resumeToken string, fn func(*blogEvent) error) error
+
+	// Ping checks the store can serve requests
+	Ping(ctx context.Context) error
+
+	// Close releases the resources held by the store
+	Close(ctx context.Context) error
+}
+
+// CommentStore is the storage used by the CommentService server.
+// Implementations must be safe for concurrent use. Purging a blog
+// in the BlogStore deletes its comments
+type CommentStore interface {
+	// CreateComment stores a new comment and returns it with the generated ID
+	// and the create time. Returns errBlogNotFound if the blog is not found
+	// or soft deleted and errParentNotFound if the parent comment is not
+	// a comment of the blog
+	CreateComment(ctx context.Context, comment *blogpb.Comment) (*blogpb.Comment, error)
+
+	// ListComments calls fn for every comment of the blog oldest first.
+	// Returns errBlogNotFound if the blog is not found or soft deleted
+	ListComments(ctx context.Context, blogID string, fn func(*blogpb.Comment) error) error
+
+	// ReadComment returns the comment with the id.
+	// Returns errCommentNotFound if there is no comment with the id
+	ReadComment(ctx context.Context, id string) (*blogpb.Comment, error)
+
+	// DeleteComment deletes the comment with all the replies to it and
+	// returns the number of deleted comments.
+	// Returns errCommentNotFound if there is no comment with the id
+	DeleteComment(ctx context.Context, id string) (int64, error)
+}
+
+// AuthorStore is the storage used by the AuthorService server.
+// Implementations must be safe for concurrent use. Authors are never
+// deleted so blogs can not lose their author
+type AuthorStore interface {
+	// CreateAuthor stores a new author and returns it with the generated ID
+	// and the create and update times
+	CreateAuthor(ctx context.Context, author *blogpb.Author) (*blogpb.Author, error)
+
+	// ReadAuthor returns errAuthorNotFound if there is no author with the id
+	ReadAuthor(ctx context.Context, id string) (*blogpb.Author, error)
+
+	// UpdateAuthor sets the fields (see authorUpdatableFields) of the author
+	// with the same ID and the update time.
+	// Returns errAuthorNotFound if there is no author with the id
+	UpdateAuthor(ctx context.Context, author *blogpb.Author, fields []string) (*blogpb.Author, error)
+
+	// ListAuthors calls fn for every author oldest first
+	ListAuthors(ctx context.Context, fn func(*blogpb.Author) error) error
+}
+
+// batchResult is the result of one item of a batch store call
+type batchResult struct {
+	Blog *blogpb.Blog
+	Err  error
+}
+
+// updatableFields are the Blog fields which can be changed by UpdateBlog
+var updatableFields = []string{"author_id", "title", "content", "tags", "category"}
+
+// normalizeTags returns the tags lower case, sorted and without duplicates
+// or empty tags
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// setBlogFields copies the fields from src to dst
+func setBlogFields(dst, src *blogpb.Blog, fields []string) {
+	for _, field := range fields {
+		switch field {
+		case "author_id":
+			dst.AuthorId = src.GetAuthorId()
+		case "title":
+			dst.Title = src.GetTitle()
+		case "content":
+			dst.Content = src.GetContent()
+		case "tags":
+			dst.Tags = normalizeTags(src.GetTags())
+		case "category":
+			dst.Category = src.GetCategory()
+		}
+	}
+}
+
+// writer is the identity of the client making the write, empty without
+// a verified client certificate
+func writer(ctx context.Context) string {
+	if id, ok := identity.FromContext(ctx); ok {
+		return id.Name()
+	}
+
+	return ""
+}
+
+// authorUpdatableFields are the Author fields which can be changed by UpdateAuthor
+var authorUpdatableFields = []string{"display_name", "email", "bio"}
+
+// setAuthorFields copies the fields from src to dst
+func setAuthorFields(dst, src *blogpb.Author, fields []string) {
+	for _, field := range fields {
+		switch field {
+		case "display_name":
+			dst.DisplayName = src.GetDisplayName()
+		case "email":
+			dst.Email = src.GetEmail()
+		case "bio":
+			dst.Bio = src.GetBio()
+		}
+	}
+}
+
+// containsString reports if the value is one of the values
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// statusFrom lists the statuses a blog can move to the status from
+var statusFrom = map[blogpb.Blog_Status][]blogpb.Blog_Status{
+	blogpb.Blog_DRAFT:     {blogpb.Blog_SCHEDULED, blogpb.Blog_PUBLISHED},
+	blogpb.Blog_SCHEDULED: {blogpb.Blog_DRAFT, blogpb.Blog_SCHEDULED, blogpb.Blog_ARCHIVED},
+	blogpb.Blog_PUBLISHED: {blogpb.Blog_DRAFT, blogpb.Blog_SCHEDULED, blogpb.Blog_ARCHIVED},
+	blogpb.Blog_ARCHIVED:  {blogpb.Blog_DRAFT, blogpb.Blog_SCHEDULED, blogpb.Blog_PUBLISHED},
+}
+
+// checkStatus tells if the stored blog can move to the status
+func checkStatus(stored *blogpb.Blog, status blogpb.Blog_Status) error {
+	if !containsStatus(statusFrom[status], stored.GetStatus()) {
+		return fmt.Errorf("%w from %v to %v", errInvalidStatus, stored.GetStatus(), status)
+	}
+
+	return nil
+}
+
+// setStatus changes the status and the publish time like BlogStore.SetStatus
+func setStatus(blog *blogpb.Blog, status blogpb.Blog_Status, publishTime time.Time) {
+	blog.Status = status
+	switch status {
+	case blogpb.Blog_DRAFT:
+		blog.PublishTime = nil
+	case blogpb.Blog_SCHEDULED, blogpb.Blog_PUBLISHED:
+		blog.PublishTime = timestampProto(publishTime)
+	}
+}
+
+func containsStatus(statuses []blogpb.Blog_Status, status blogpb.Blog_Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkWrite tells if a write can change the stored blog.
+// deleted is true for writes of soft deleted blogs (Undelete)
+func checkWrite(stored *blogpb.Blog, expectedRevision int64, deleted bool) error {
+	isDeleted := stored.GetDeleteTime() != nil
+	if isDeleted && !deleted {
+		return withID(errBlogNotFound, stored.GetId())
+	}
+	if expectedRevision != 0 && stored.GetRevision() != expectedRevision {
+		return withID(errRevisionMismatch, stored.GetId())
+	}
+	if !isDeleted && deleted {
+		return withID(errBlogNotDeleted, stored.GetId())
+	}
+
+	return nil
+}
+
+// timestampProto converts the time for the Blog timestamps.
+// Zero time is not set
+func timestampProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
+
+// blogCursor is the position of the last blog of the previous page
+type blogCursor struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+}
+
+// blogQuery filters and orders the List results
+type blogQuery struct {
+	AuthorID    string
+	TitlePrefix string
+	// Tags are normalized, see normalizeTags
+	Tags     []string
+	AllTags  bool
+	Category string
+	// Statuses are the allowed statuses, empty allows all
+	Statuses []blogpb.Blog_Status
+	Order    blogpb.ListBlogRequest_Order
+
+	ShowDeleted bool
+	// After skips all blogs up to and including the cursor
+	After *blogCursor
+	// Limit is the max number of blogs. 0 means no limit
+	Limit int
+}
+
+// byTitle reports if the blogs are ordered by title instead of the ID
+func (q *blogQuery) byTitle() bool {
+	return q.Order == blogpb.ListBlogRequest_TITLE_ASC || q.Order == blogpb.ListBlogRequest_TITLE_DESC
+}
+
+// descending reports if the order is reversed
+func (q *blogQuery) descending() bool {
+	return q.Order == blogpb.ListBlogRequest_CREATED_DESC || q.Order == blogpb.ListBlogRequest_TITLE_DESC
+}
+
+// matches reports if the blog passes the query filters
+func (q *blogQuery) matches(blog *blogpb.Blog) bool {
+	if !q.ShowDeleted && blog.GetDeleteTime() != nil {
+		return false
+	}
+	if q.AuthorID != "" && blog.GetAuthorId() != q.AuthorID {
+		return false
+	}
+	if len(q.Statuses) > 0 && !containsStatus(q.Statuses, blog.GetStatus()) {
+		return false
+	}
+	if q.Category != "" && blog.GetCategory() != q.Category {
+		return false
+	}
+	if len(q.Tags) > 0 && !q.matchesTags(blog.GetTags()) {
+		return false
+	}
+
+	return strings.HasPrefix(blog.GetTitle(), q.TitlePrefix)
+}
+
+// matchesTags reports if the tags have any or all (AllTags) of the query tags
+func (q *blogQuery) matchesTags(tags []string) bool {
+	count := 0
+	for _, tag := range q.Tags {
+		if containsString(tags, tag) {
+			count++
+		}
+	}
+	if q.AllTags {
+		return count == len(q.Tags)
+	}
+
+	return count > 0
+}
+
+// tagFacets are the blog counts returned by BlogStore.Facets
+type tagFacets struct {
+	Tags       map[string]int64
+	Categories map[string]int64
+}
+
+// before reports if blog a goes before blog b in the query order
+func (q *blogQuery) before(a, b *blogpb.Blog) bool {
+	if q.byTitle() && a.GetTitle() != b.GetTitle() {
+		return (a.GetTitle() < b.GetTitle()) != q.descending()
+	}
+	if a.GetId() == b.GetId() {
+		return false
+	}
+
+	return (a.GetId() < b.GetId()) != q.descending()
+}
+
+// parseObjectID validates blog, comment and author ids.
+// All stores use mongodb ObjectID hex ids
+func parseObjectID(id string) (primitive.ObjectID, error) {
+	objid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return objid, withID(errInvalidID, id)
+	}
+
+	return objid, nil
+}
@@ -0,0 +1,143 @@
+package blogserver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestUpdateBlogMask(t *testing.T) {
+	s, author := newTestServer(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		update *blogpb.Blog
+		paths  []string
+		want   func(blog *blogpb.Blog)
+	}{
+		{
+			"title only",
+			&blogpb.Blog{Title: "new title", Content: "ignored"},
+			[]string{"title"},
+			func(blog *blogpb.Blog) { blog.Title = "new title" },
+		},
+		{
+			"tags normalized",
+			&blogpb.Blog{Tags: []string{"Go", "grpc", "go"}},
+			[]string{"tags"},
+			func(blog *blogpb.Blog) { blog.Tags = []string{"go", "grpc"} },
+		},
+		{
+			"empty mask updates all",
+			&blogpb.Blog{AuthorId: author, Title: "all", Content: "all content"},
+			nil,
+			func(blog *blogpb.Blog) {
+				blog.Title, blog.Content, blog.Tags, blog.Category = "all", "all content", nil, ""
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "title", Content: "content", Tags: []string{"old"}, Category: "news"})
+			tt.update.Id = old.GetId()
+
+			res, err := s.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{Blog: tt.update, UpdateMask: &fieldmaskpb.FieldMask{Paths: tt.paths}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := res.GetBlog()
+			want := &blogpb.Blog{AuthorId: author, Title: "title", Content: "content", Tags: []string{"old"}, Category: "news"}
+			tt.want(want)
+			if got.GetTitle() != want.GetTitle() || got.GetContent() != want.GetContent() ||
+				!reflect.DeepEqual(got.GetTags(), want.GetTags()) || got.GetCategory() != want.GetCategory() {
+				t.Errorf("UpdateBlog() = %v, want %v", got, want)
+			}
+			if got.GetRevision() != old.GetRevision()+1 {
+				t.Errorf("revision = %d, want %d", got.GetRevision(), old.GetRevision()+1)
+			}
+		})
+	}
+}
+
+func TestUpdateBlogInvalidMask(t *testing.T) {
+	s, author := newTestServer(t)
+	blog := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "title", Content: "content"})
+
+	tests := []struct {
+		name  string
+		paths []string
+	}{
+		{"not updatable", []string{"status"}},
+		{"unknown", []string{"nope"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.UpdateBlog(context.Background(), &blogpb.UpdateBlogRequest{
+				Blog:       &blogpb.Blog{Id: blog.GetId(), Title: "x"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: tt.paths},
+			})
+			wantReason(t, err, codes.InvalidArgument, "INVALID_UPDATE_MASK")
+		})
+	}
+}
+
+func TestUpdateBlogExpectedRevision(t *testing.T) {
+	s, author := newTestServer(t)
+	blog := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "title", Content: "content"})
+
+	// the cases update the same blog in order, revision 1 is stale after
+	// the first one
+	tests := []struct {
+		name     string
+		expected int64
+		wantErr  bool
+	}{
+		{"current", 1, false},
+		{"stale", 1, true},
+		{"not checked", 0, false},
+		{"ahead", 10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.UpdateBlog(context.Background(), &blogpb.UpdateBlogRequest{
+				Blog:             &blogpb.Blog{Id: blog.GetId(), Title: tt.name},
+				UpdateMask:       &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+				ExpectedRevision: tt.expected,
+			})
+			if tt.wantErr {
+				wantReason(t, err, codes.Aborted, "REVISION_MISMATCH")
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.GetBlog().GetTitle() != tt.name {
+				t.Errorf("title = %q, want %q", res.GetBlog().GetTitle(), tt.name)
+			}
+		})
+	}
+}
+
+func TestUnknownAuthor(t *testing.T) {
+	s, author := newTestServer(t)
+	ctx := context.Background()
+
+	_, err := s.CreateBlog(ctx, &blogpb.CreateBlogRequest{Blog: &blogpb.Blog{AuthorId: "unknown", Title: "title"}})
+	wantReason(t, err, codes.FailedPrecondition, "UNKNOWN_AUTHOR")
+
+	blog := createTestBlog(t, s, &blogpb.Blog{AuthorId: author, Title: "title", Content: "content"})
+	_, err = s.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{
+		Blog:       &blogpb.Blog{Id: blog.GetId(), AuthorId: "unknown"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"author_id"}},
+	})
+	wantReason(t, err, codes.FailedPrecondition, "UNKNOWN_AUTHOR")
+}
@@ -5,20 +5,25 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"time"
 
 	"github.com/KestutisKazlauskas/grpc-go/calculator/calculatorpb"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/KestutisKazlauskas/grpc-go/config"
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
 )
 
 func main() {
 
 	fmt.Println("Client started")
 
-	//connection without ssl
-	conn, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
+	cfg := &config.Client{Target: "localhost:50051"}
+	if err := config.Load("CALCULATOR_CLIENT", cfg, os.Args[1:]); err != nil {
+		log.Fatalf("Config error %v", err)
+	}
+
+	//connection without ssl unless tls.enabled
+	conn, err := cfg.Dial()
 	if err != nil {
 		log.Fatalf("Error on initiatning connection %v", err)
 	}
@@ -155,12 +160,11 @@ func doSqrtCall(c calculatorpb.CalculatorServiceClient, number int32) {
 	res, err := c.SquareRoot(context.Background(), &calculatorpb.SquareRootRequest{Number: number})
 
 	if err != nil {
-		respErr, ok := status.FromError(err)
+		respErr, ok := rpcerror.FromError(err)
 		if ok {
 			//this is a user errror
-			fmt.Println(respErr.Message())
-			fmt.Println(respErr.Code())
-			if respErr.Code() == codes.InvalidArgument {
+			fmt.Println(respErr)
+			if respErr.Reason() == "NEGATIVE_NUMBER" {
 				fmt.Println("Send negative number!")
 				return
 			}
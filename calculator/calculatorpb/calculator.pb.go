@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.21.0-devel
+// 	protoc-gen-go v1.23.0
 // 	protoc        v3.11.4
 // source: calculator/calculatorpb/calculator.proto
 
@@ -29,6 +29,7 @@ const (
 // of the legacy proto package is being used.
 const _ = proto.ProtoPackageIsVersion4
 
+// Sum fails with InvalidArgument when x + y does not fit in int32
 type SumRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -784,6 +785,8 @@ type CalculatorServiceClient interface {
 	//Bi-Di streamin
 	Max(ctx context.Context, opts ...grpc.CallOption) (CalculatorService_MaxClient, error)
 	//Error handling
+	// rpc throws errros if number is a nagative
+	// The errro beenig send is of type INVALID_ARGUMENT
 	SquareRoot(ctx context.Context, in *SquareRootRequest, opts ...grpc.CallOption) (*SquareRootResponse, error)
 }
 
@@ -921,6 +924,8 @@ type CalculatorServiceServer interface {
 	//Bi-Di streamin
 	Max(CalculatorService_MaxServer) error
 	//Error handling
+	// rpc throws errros if number is a nagative
+	// The errro beenig send is of type INVALID_ARGUMENT
 	SquareRoot(context.Context, *SquareRootRequest) (*SquareRootResponse, error)
 }
 
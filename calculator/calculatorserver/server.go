@@ -0,0 +1,136 @@
+// Package calculatorserver implements the CalculatorService
+package calculatorserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+
+	"github.com/KestutisKazlauskas/grpc-go/calculator/calculatorpb"
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type server struct{}
+
+func (s *server) Sum(ctx context.Context, req *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
+	fmt.Printf("Calculator was called %v", req)
+
+	sum := int64(req.GetX()) + int64(req.GetY())
+	if sum < math.MinInt32 || sum > math.MaxInt32 {
+		return nil, rpcerror.New(codes.InvalidArgument, "SUM_OVERFLOW", fmt.Sprintf("Sum %d does not fit in int32", sum)).
+			WithViolation("x", "sum with y does not fit in int32").
+			WithViolation("y", "sum with x does not fit in int32").
+			Err()
+	}
+
+	res := &calculatorpb.SumResponse{
+		Result: int32(sum),
+	}
+
+	return res, nil
+}
+
+func (s *server) PrimeNumberDecomposition(req *calculatorpb.PrimeNumberDecompositionRequest, stream calculatorpb.CalculatorService_PrimeNumberDecompositionServer) error {
+	var primeNumber int32 = 2
+	number := req.GetNumber()
+
+	for number > 1 {
+		if number%primeNumber == 0 {
+			res := &calculatorpb.PrimeNumberDecompositionResponse{
+				PrimeNubmer: primeNumber,
+			}
+			stream.Send(res)
+			//send to stream
+			number = number / primeNumber
+		} else {
+			primeNumber = primeNumber + 1
+		}
+	}
+	return nil
+}
+
+func (s *server) Average(stream calculatorpb.CalculatorService_AverageServer) error {
+
+	var sum int32
+	var length int32
+	var avg float64
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			log.Printf("Length %d", length)
+			log.Printf("Sum: %v", sum)
+
+			avg = float64(sum) / float64(length)
+			return stream.SendAndClose(&calculatorpb.AverageResponse{Avg: avg})
+		}
+
+		if err != nil {
+			log.Printf("Error on streaming calculator: %v", err)
+			return err
+		}
+		log.Printf("Recievied %d", req.GetNumber())
+		sum += req.GetNumber()
+		length++
+	}
+
+}
+
+func (s *server) Max(stream calculatorpb.CalculatorService_MaxServer) error {
+	log.Println("Max stream was called")
+
+	var max int32 = 0
+	var isFirstRequest bool = true
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			log.Printf("Max stream erro on request streaming %v", err)
+			return err
+		}
+
+		currentNumber := req.GetNumber()
+
+		if isFirstRequest || max < currentNumber {
+			max = currentNumber
+			sendErr := stream.Send(&calculatorpb.MaxResponse{CurrentMax: max})
+			if sendErr != nil {
+				log.Printf("Error on sending response to Max stream: %v", sendErr)
+				return sendErr
+			}
+		}
+
+		if isFirstRequest {
+			isFirstRequest = false
+		}
+
+	}
+
+	return nil
+}
+
+func (s *server) SquareRoot(ctx context.Context, req *calculatorpb.SquareRootRequest) (*calculatorpb.SquareRootResponse, error) {
+	number := req.GetNumber()
+
+	if number < 0 {
+		return nil, rpcerror.New(codes.InvalidArgument, "NEGATIVE_NUMBER", fmt.Sprintf("Recieved negative number %v", number)).
+			WithViolation("number", "must not be negative").
+			Err()
+	}
+	return &calculatorpb.SquareRootResponse{
+		NumberRoot: math.Sqrt(float64(number)),
+	}, nil
+}
+
+// Register adds the service to the server
+func Register(s *grpc.Server) {
+	calculatorpb.RegisterCalculatorServiceServer(s, &server{})
+}
@@ -0,0 +1,185 @@
+// Package certs keeps the TLS certificate of a server up to date with its
+// files so a rotated certificate is used without a restart
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Manager is the certificate, key and client CA bundle loaded from
+// their files. The new handshakes use the last loaded files while the
+// open connections keep the certificate they started with
+type Manager struct {
+	certFile, keyFile, caFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	// stamps are the modification times and sizes of the loaded files
+	stamps map[string]fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewManager loads the certificate and the key and the client CA bundle
+// if caFile is not empty
+func NewManager(certFile, keyFile, caFile string) (*Manager, error) {
+	m := &Manager{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// files are the watched files
+func (m *Manager) files() []string {
+	files := []string{m.certFile, m.keyFile}
+	if m.caFile != "" {
+		files = append(files, m.caFile)
+	}
+
+	return files
+}
+
+// Reload loads all the files again and replaces the certificate and the
+// client CA bundle together. The loaded ones are kept on errors
+func (m *Manager) Reload() error {
+	stamps, err := statFiles(m.files())
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(m.certFile, m.keyFile)
+	if err != nil {
+		return fmt.Errorf("cannot load the certificate: %v", err)
+	}
+	var clientCAs *x509.CertPool
+	if m.caFile != "" {
+		clientCAs, err = LoadCertPool(m.caFile)
+		if err != nil {
+			return fmt.Errorf("cannot load the client CA bundle: %v", err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cert = &cert
+	m.clientCAs = clientCAs
+	m.stamps = stamps
+
+	return nil
+}
+
+// changed tells if any of the files changed since they were loaded
+func (m *Manager) changed() bool {
+	stamps, err := statFiles(m.files())
+	if err != nil {
+		// a file being replaced, checked again on the next tick
+		return false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for file, stamp := range stamps {
+		if m.stamps[file] != stamp {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Watch reloads the files on SIGHUP and when they change, checking them
+// every interval. Files are not checked if interval is 0. Returns when
+// ctx is done
+func (m *Manager) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Println("Received SIGHUP, reloading the certificates")
+		case <-tick:
+			if !m.changed() {
+				continue
+			}
+			log.Println("Certificate files changed, reloading the certificates")
+		}
+
+		if err := m.Reload(); err != nil {
+			log.Printf("Error on reloading the certificates, keeping the loaded ones: %v", err)
+		}
+	}
+}
+
+// ServerConfig returns a TLS config using the last loaded certificate and
+// client CA bundle for every handshake
+func (m *Manager) ServerConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			m.mu.RLock()
+			defer m.mu.RUnlock()
+
+			return &tls.Config{
+				Certificates: []tls.Certificate{*m.cert},
+				ClientAuth:   clientAuth,
+				ClientCAs:    m.clientCAs,
+				// grpc needs http2 to be negotiated
+				NextProtos: []string{"h2"},
+			}, nil
+		},
+	}
+}
+
+// LoadCertPool reads the PEM certificates of the file
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("no certificates in %s", file)
+	}
+
+	return pool, nil
+}
+
+func statFiles(files []string) (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		stamps[file] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	return stamps, nil
+}
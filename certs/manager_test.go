@@ -0,0 +1,155 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// writeCert writes a self signed certificate with the serial and its key
+// to the files. The modification time is moved by the serial so a quick
+// rewrite is seen as a change
+func writeCert(t *testing.T, certFile, keyFile string, serial int64) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writePEM(t, certFile, "CERTIFICATE", der, serial)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER, serial)
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte, serial int64) {
+	t.Helper()
+	if err := ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Duration(serial) * time.Minute)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// servedSerial returns the serial of the certificate used by a new handshake
+func servedSerial(t *testing.T, m *Manager) int64 {
+	t.Helper()
+	cfg, err := m.ServerConfig(tls.NoClientCert).GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert.SerialNumber.Int64()
+}
+
+func testFiles(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+}
+
+func TestManagerReload(t *testing.T) {
+	certFile, keyFile := testFiles(t)
+	writeCert(t, certFile, keyFile, 1)
+	m, err := NewManager(certFile, keyFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := servedSerial(t, m); got != 1 {
+		t.Fatalf("serial = %d, want 1", got)
+	}
+	if m.changed() {
+		t.Error("changed() = true right after the load")
+	}
+
+	writeCert(t, certFile, keyFile, 2)
+	if !m.changed() {
+		t.Error("changed() = false after the files were replaced")
+	}
+	if err := m.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := servedSerial(t, m); got != 2 {
+		t.Errorf("serial after Reload() = %d, want 2", got)
+	}
+
+	// a broken file keeps the loaded certificate
+	if err := ioutil.WriteFile(keyFile, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Reload(); err == nil {
+		t.Error("Reload() of a broken key = nil, want an error")
+	}
+	if got := servedSerial(t, m); got != 2 {
+		t.Errorf("serial after a failed Reload() = %d, want 2", got)
+	}
+
+	if _, err := NewManager(certFile, keyFile, ""); err == nil {
+		t.Error("NewManager() of a broken key = nil, want an error")
+	}
+}
+
+func TestManagerWatch(t *testing.T) {
+	certFile, keyFile := testFiles(t)
+	writeCert(t, certFile, keyFile, 1)
+	m, err := NewManager(certFile, keyFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Watch(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	writeCert(t, certFile, keyFile, 2)
+	deadline := time.Now().Add(5 * time.Second)
+	for servedSerial(t, m) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("the changed files were not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
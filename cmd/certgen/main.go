@@ -0,0 +1,334 @@
+// Command certgen writes a development CA and the server and client
+// certificates signed by it:
+//
+//	certgen -out=ssl -key-type=ecdsa -server-sans=localhost,127.0.0.1
+//
+// The files are ca.crt and ca.key, server.crt and server.pem and
+// client.crt and client.pem, the keys are unencrypted PKCS #8. An existing
+// CA in the directory is reused unless -new-ca is set or it does not match
+// the settings: another key type or size, or it expires before the new
+// certificates. The settings can also come from CERTGEN_ variables, see the
+// config package
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/config"
+)
+
+type certConfig struct {
+	Out        string        `config:"out" usage:"Directory the files are written to"`
+	KeyType    string        `config:"key-type" usage:"Key type: rsa, ecdsa or ed25519"`
+	RSABits    int           `config:"rsa-bits" usage:"Size of the rsa keys"`
+	Validity   time.Duration `config:"validity" usage:"How long the server and client certificates are valid"`
+	CAValidity time.Duration `config:"ca-validity" usage:"How long a new CA certificate is valid"`
+	NewCA      bool          `config:"new-ca" usage:"Generate a new CA even if the directory has one"`
+	CAName     string        `config:"ca-name" usage:"Common name of a new CA"`
+	ServerName string        `config:"server-name" usage:"Common name of the server certificate"`
+	ServerSANs []string      `config:"server-sans" usage:"DNS names, IPs, URIs or emails of the server certificate"`
+	ClientName string        `config:"client-name" usage:"Common name of the client certificate"`
+	ClientSANs []string      `config:"client-sans" usage:"DNS names, IPs, URIs or emails of the client certificate, the identity of the client with mutual TLS"`
+}
+
+func (c *certConfig) Validate() error {
+	switch c.KeyType {
+	case "rsa", "ecdsa", "ed25519":
+	default:
+		return fmt.Errorf("unknown key-type %q, use rsa, ecdsa or ed25519", c.KeyType)
+	}
+	if c.KeyType == "rsa" && c.RSABits < 2048 {
+		return errors.New("rsa-bits must be at least 2048")
+	}
+	if c.Validity <= 0 || c.CAValidity <= 0 {
+		return errors.New("validity and ca-validity must be positive")
+	}
+	if len(c.ServerSANs) == 0 {
+		return errors.New("server-sans can not be empty, clients check the server by its SANs")
+	}
+
+	return nil
+}
+
+func main() {
+	cfg := &certConfig{
+		Out:        "ssl",
+		KeyType:    "ecdsa",
+		RSABits:    2048,
+		Validity:   365 * 24 * time.Hour,
+		CAValidity: 10 * 365 * 24 * time.Hour,
+		CAName:     "grpc-go development CA",
+		ServerName: "localhost",
+		ServerSANs: []string{"localhost", "127.0.0.1", "::1"},
+		ClientName: "grpc-go-client",
+		ClientSANs: []string{"spiffe://grpc-go/client"},
+	}
+	if err := config.Load("CERTGEN", cfg, os.Args[1:]); err != nil {
+		log.Fatalf("Config error %v", err)
+	}
+
+	if err := os.MkdirAll(cfg.Out, 0755); err != nil {
+		log.Fatalf("Cannot create the directory %v", err)
+	}
+
+	ca, caKey, err := loadCA(cfg)
+	if err != nil {
+		log.Fatalf("Cannot load the CA %v", err)
+	}
+	if ca != nil {
+		if reason := caMismatch(cfg, ca, caKey); reason != "" {
+			fmt.Printf("Replacing the CA %s, %s\n", filepath.Join(cfg.Out, "ca.crt"), reason)
+			ca, caKey = nil, nil
+		}
+	}
+	if ca == nil {
+		ca, caKey, err = newCA(cfg)
+		if err != nil {
+			log.Fatalf("Cannot create the CA %v", err)
+		}
+		fmt.Printf("Created the CA %s\n", filepath.Join(cfg.Out, "ca.crt"))
+	} else {
+		fmt.Printf("Using the CA %s\n", filepath.Join(cfg.Out, "ca.crt"))
+	}
+
+	leafs := []struct {
+		name  string
+		cn    string
+		sans  []string
+		usage x509.ExtKeyUsage
+	}{
+		{"server", cfg.ServerName, cfg.ServerSANs, x509.ExtKeyUsageServerAuth},
+		{"client", cfg.ClientName, cfg.ClientSANs, x509.ExtKeyUsageClientAuth},
+	}
+	for _, leaf := range leafs {
+		tmpl, err := template(leaf.cn, cfg.Validity)
+		if err != nil {
+			log.Fatalf("Cannot create the %s certificate %v", leaf.name, err)
+		}
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{leaf.usage}
+		if err := setSANs(tmpl, leaf.sans); err != nil {
+			log.Fatalf("Invalid %s SANs %v", leaf.name, err)
+		}
+
+		if _, err := writeCert(cfg, leaf.name, tmpl, ca, caKey); err != nil {
+			log.Fatalf("Cannot write the %s certificate %v", leaf.name, err)
+		}
+		fmt.Printf("Created %s and %s\n", filepath.Join(cfg.Out, leaf.name+".crt"), filepath.Join(cfg.Out, leaf.name+".pem"))
+	}
+}
+
+// loadCA reads the CA of the out directory. Returns nil if there is none
+// or a new one is asked for
+func loadCA(cfg *certConfig) (*x509.Certificate, crypto.Signer, error) {
+	certFile, keyFile := filepath.Join(cfg.Out, "ca.crt"), filepath.Join(cfg.Out, "ca.key")
+	if cfg.NewCA {
+		return nil, nil, nil
+	}
+	if _, err := os.Stat(certFile); os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+
+	certDER, err := readPEM(certFile, "CERTIFICATE")
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := readPEM(keyFile, "PRIVATE KEY")
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v, use -new-ca to replace a CA not created by certgen", err)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(keyDER)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported key in %s", keyFile)
+	}
+
+	return cert, signer, nil
+}
+
+// caMismatch tells why the loaded CA can not sign the certificates asked
+// for, empty if it can
+func caMismatch(cfg *certConfig, ca *x509.Certificate, key crypto.Signer) string {
+	var keyType string
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		keyType = "rsa"
+		if cfg.KeyType == "rsa" && key.N.BitLen() != cfg.RSABits {
+			return fmt.Sprintf("its rsa key has %d bits, not %d", key.N.BitLen(), cfg.RSABits)
+		}
+	case *ecdsa.PrivateKey:
+		keyType = "ecdsa"
+	case ed25519.PrivateKey:
+		keyType = "ed25519"
+	}
+	if keyType != cfg.KeyType {
+		return fmt.Sprintf("its key is %s, not %s", keyType, cfg.KeyType)
+	}
+	if !ca.IsCA {
+		return "it is not a CA certificate"
+	}
+
+	// the certificates are not valid after the CA expires
+	if expires := time.Now().Add(cfg.Validity); ca.NotAfter.Before(expires) {
+		return fmt.Sprintf("it expires on %s, before the new certificates", ca.NotAfter.Format("2006-01-02"))
+	}
+
+	return ""
+}
+
+// newCA writes a new self signed CA
+func newCA(cfg *certConfig) (*x509.Certificate, crypto.Signer, error) {
+	tmpl, err := template(cfg.CAName, cfg.CAValidity)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+
+	key, err := writeCert(cfg, "ca", tmpl, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Parsed back to have the raw subject and the key id of the parent
+	der, err := readPEM(filepath.Join(cfg.Out, "ca.crt"), "CERTIFICATE")
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cert, key, nil
+}
+
+// template is a certificate with a random serial number valid from now
+func template(cn string, validity time.Duration) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		// a little earlier for the clocks behind
+		NotBefore: now.Add(-5 * time.Minute),
+		NotAfter:  now.Add(validity),
+	}, nil
+}
+
+// setSANs sorts the names into IP, URI, email and DNS SANs
+func setSANs(tmpl *x509.Certificate, sans []string) error {
+	for _, san := range sans {
+		switch {
+		case net.ParseIP(san) != nil:
+			tmpl.IPAddresses = append(tmpl.IPAddresses, net.ParseIP(san))
+		case strings.Contains(san, "://"):
+			uri, err := url.Parse(san)
+			if err != nil {
+				return err
+			}
+			tmpl.URIs = append(tmpl.URIs, uri)
+		case strings.Contains(san, "@"):
+			tmpl.EmailAddresses = append(tmpl.EmailAddresses, san)
+		case san != "":
+			tmpl.DNSNames = append(tmpl.DNSNames, san)
+		}
+	}
+
+	return nil
+}
+
+// newKey generates a key of the configured type
+func newKey(cfg *certConfig) (crypto.Signer, error) {
+	switch cfg.KeyType {
+	case "rsa":
+		return rsa.GenerateKey(rand.Reader, cfg.RSABits)
+	case "ed25519":
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+// writeCert generates a key, signs the certificate with the parent or
+// self signs it without one and writes name.crt and the key. The CA key
+// is name.key, the others name.pem like the servers expect
+func writeCert(cfg *certConfig, name string, tmpl, parent *x509.Certificate, parentKey crypto.Signer) (crypto.Signer, error) {
+	key, err := newKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	keyFile := name + ".pem"
+	if tmpl.IsCA {
+		keyFile = name + ".key"
+	}
+	if err := writePEM(filepath.Join(cfg.Out, keyFile), "PRIVATE KEY", keyDER, 0600); err != nil {
+		return nil, err
+	}
+	if err := writePEM(filepath.Join(cfg.Out, name+".crt"), "CERTIFICATE", der, 0644); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func writePEM(file, blockType string, der []byte, perm os.FileMode) error {
+	return ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), perm)
+}
+
+func readPEM(file, blockType string) ([]byte, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("no %s in %s", blockType, file)
+	}
+
+	return block.Bytes, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCAMismatch(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	year := 365 * 24 * time.Hour
+	ca := &x509.Certificate{IsCA: true, NotAfter: time.Now().Add(10 * year)}
+
+	tests := []struct {
+		name string
+		cfg  certConfig
+		ca   *x509.Certificate
+		key  crypto.Signer
+		want string
+	}{
+		{"ecdsa", certConfig{KeyType: "ecdsa", Validity: year}, ca, ecdsaKey, ""},
+		{"ed25519", certConfig{KeyType: "ed25519", Validity: year}, ca, ed25519Key, ""},
+		{"rsa", certConfig{KeyType: "rsa", RSABits: 1024, Validity: year}, ca, rsaKey, ""},
+		{"rsa bits", certConfig{KeyType: "rsa", RSABits: 2048, Validity: year}, ca, rsaKey, "its rsa key has 1024 bits, not 2048"},
+		{"key type", certConfig{KeyType: "ecdsa", Validity: year}, ca, ed25519Key, "its key is ed25519, not ecdsa"},
+		{"rsa key for ecdsa", certConfig{KeyType: "ecdsa", RSABits: 2048, Validity: year}, ca, rsaKey, "its key is rsa, not ecdsa"},
+		{"not a CA", certConfig{KeyType: "ecdsa", Validity: year}, &x509.Certificate{NotAfter: ca.NotAfter}, ecdsaKey, "it is not a CA certificate"},
+		{"expires first", certConfig{KeyType: "ecdsa", Validity: 11 * year}, ca, ecdsaKey, "it expires on"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := caMismatch(&tt.cfg, tt.ca, tt.key)
+			if tt.want == "" && got != "" || !strings.HasPrefix(got, tt.want) {
+				t.Errorf("caMismatch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
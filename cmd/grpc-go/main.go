@@ -0,0 +1,111 @@
+// Command grpc-go serves any of the greet, calculator and blog services
+// on one listener:
+//
+//	grpc-go -services=greet,calculator -listen=0.0.0.0:50051
+//
+// The settings can also come from GRPC_GO_ variables and a -config file,
+// see the config package
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogserver"
+	"github.com/KestutisKazlauskas/grpc-go/calculator/calculatorserver"
+	"github.com/KestutisKazlauskas/grpc-go/config"
+	"github.com/KestutisKazlauskas/grpc-go/greet/greetserver"
+	"github.com/KestutisKazlauskas/grpc-go/grpcserver"
+
+	"golang.org/x/net/context"
+)
+
+type serverConfig struct {
+	config.Server
+	Services []string          `config:"services" usage:"Comma separated services to serve: greet, calculator, blog"`
+	Blog     blogserver.Config `config:"blog"`
+}
+
+func (c *serverConfig) Validate() error {
+	if len(c.Services) == 0 {
+		return errors.New("services can not be empty")
+	}
+	for _, name := range c.Services {
+		switch name {
+		case "greet", "calculator", "blog":
+		default:
+			return fmt.Errorf("unknown service %q, use greet, calculator or blog", name)
+		}
+	}
+
+	return nil
+}
+
+func (c *serverConfig) serves(name string) bool {
+	for _, service := range c.Services {
+		if service == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func main() {
+	// if somthing crash in go code
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	cfg := &serverConfig{
+		Server:   config.DefaultServer(),
+		Services: []string{"greet", "calculator", "blog"},
+		Blog:     blogserver.DefaultConfig(),
+	}
+	if err := config.Load("GRPC_GO", cfg, os.Args[1:]); err != nil {
+		log.Fatalf("Config error %v", err)
+	}
+
+	s, err := grpcserver.New(cfg.Server)
+	if err != nil {
+		log.Fatalf("Server error %v", err)
+	}
+
+	if cfg.serves("greet") {
+		greetserver.Register(s.Server)
+	}
+	if cfg.serves("calculator") {
+		calculatorserver.Register(s.Server)
+	}
+	var blog *blogserver.Service
+	if cfg.serves("blog") {
+		blog, err = blogserver.New(cfg.Blog)
+		if err != nil {
+			log.Fatalf("Store error %v", err)
+		}
+		blog.Register(s.Context(), s.Server)
+		blog.WatchHealth(s.Health)
+		s.Policy.SetOwners(blog.Owners())
+	}
+
+	listen, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		log.Fatalf("Failed to listen %v", err)
+	}
+
+	fmt.Printf("Serving %v on %s...\n", cfg.Services, cfg.Listen)
+	if err := s.ServeUntilSignal(listen, cfg.ShutdownTimeout); err != nil {
+		log.Fatalf("Failder to server %v", err)
+	}
+
+	// The store is closed after the pending RPCs finish
+	if blog != nil {
+		fmt.Println("Closing the store")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		blog.Close(ctx)
+	}
+	fmt.Println("Program ended")
+}
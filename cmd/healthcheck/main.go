@@ -0,0 +1,63 @@
+// Command healthcheck asks a server for the health of a service and exits
+// with 0 when it is SERVING and 1 otherwise, so it can be used as a
+// container probe:
+//
+//	healthcheck -target=localhost:50051 -service=blog.BlogService
+//
+// The empty service checks the whole server. The settings can also come
+// from HEALTHCHECK_ variables, see the config package
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/config"
+
+	"golang.org/x/net/context"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type checkConfig struct {
+	config.Client
+	Service string        `config:"service" usage:"Service to check, the whole server if empty"`
+	Timeout time.Duration `config:"timeout" usage:"How long the check can take"`
+}
+
+func main() {
+	cfg := &checkConfig{
+		Client:  config.Client{Target: "localhost:50051"},
+		Timeout: 5 * time.Second,
+	}
+	if err := config.Load("HEALTHCHECK", cfg, os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Config error %v\n", err)
+		os.Exit(2)
+	}
+
+	os.Exit(check(cfg))
+}
+
+func check(cfg *checkConfig) int {
+	cc, err := cfg.Dial()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not connect: %v\n", err)
+		return 1
+	}
+	defer cc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	res, err := healthpb.NewHealthClient(cc).Check(ctx, &healthpb.HealthCheckRequest{Service: cfg.Service})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Health check failed: %v\n", err)
+		return 1
+	}
+
+	fmt.Println(res.GetStatus())
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return 1
+	}
+
+	return 0
+}
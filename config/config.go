@@ -0,0 +1,365 @@
+// Package config loads the configuration of the servers and clients from
+// the defaults, a YAML or TOML file, environment variables and command line
+// flags. Later sources win:
+//
+//	defaults < config file < environment < flags
+//
+// The configuration is a struct with the defaults set. Its fields are named
+// with the config tag and described with the usage tag:
+//
+//	type serverConfig struct {
+//		config.Server
+//		Store string `config:"store" usage:"Blog storage backend: mongo or memory"`
+//	}
+//
+// A field named "store" is the -store flag, the BLOG_STORE variable with
+// the BLOG prefix and the store key of the file. Nested structs add their name
+// with a dot: -tls.cert-file, BLOG_TLS_CERT_FILE and cert-file in the tls table
+// of the file. Embedded structs without a name are flattened.
+//
+// The file is given with the -config flag or the <PREFIX>_CONFIG variable.
+// Its format is chosen by the extension: .yaml, .yml or .toml
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Validator is implemented by the configuration structs which check their
+// values after loading. Nested structs are validated before their parents
+type Validator interface {
+	Validate() error
+}
+
+// field is a settable configuration value
+type field struct {
+	name  string
+	usage string
+	value reflect.Value
+}
+
+// Load fills cfg, a pointer to a struct with the defaults, from the sources.
+// prefix is the prefix of the environment variables and args are the command
+// line arguments without the program name
+func Load(prefix string, cfg interface{}, args []string) error {
+	ptr := reflect.ValueOf(cfg)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Struct {
+		return errors.New("config must be a pointer to a struct")
+	}
+
+	fields, err := collect(ptr.Elem(), "")
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*field, len(fields))
+	for _, f := range fields {
+		byName[f.name] = f
+	}
+
+	// Flags are parsed first to find the file, they are applied last
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ExitOnError)
+	configFile := flags.String("config", os.Getenv(envName(prefix, "config")), "YAML or TOML configuration file")
+	var set []*pendingFlag
+	for _, f := range fields {
+		flags.Var(&pendingFlag{field: f, set: &set}, f.name, usage(prefix, f))
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(*configFile, byName); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range fields {
+		value, ok := os.LookupEnv(envName(prefix, f.name))
+		if !ok {
+			continue
+		}
+		if err := setValue(f.value, value); err != nil {
+			return fmt.Errorf("invalid %s: %v", envName(prefix, f.name), err)
+		}
+	}
+
+	for _, p := range set {
+		if err := setValue(p.field.value, p.value); err != nil {
+			return fmt.Errorf("invalid -%s: %v", p.field.name, err)
+		}
+	}
+
+	return validate(ptr.Elem())
+}
+
+// collect returns the fields of the struct, nested structs are flattened
+func collect(v reflect.Value, prefix string) ([]*field, error) {
+	var fields []*field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, tagged := sf.Tag.Lookup("config")
+		if name == "-" || (!tagged && !sf.Anonymous) {
+			continue
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			nested := prefix
+			if name != "" {
+				nested = prefix + name + "."
+			}
+			inner, err := collect(fv, nested)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, inner...)
+			continue
+		}
+
+		if !fv.CanSet() {
+			return nil, fmt.Errorf("config field %s is not exported", sf.Name)
+		}
+		if !supported(fv) {
+			return nil, fmt.Errorf("config field %s has unsupported type %v", sf.Name, fv.Type())
+		}
+		fields = append(fields, &field{
+			name:  prefix + name,
+			usage: sf.Tag.Get("usage"),
+			value: fv,
+		})
+	}
+
+	return fields, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func supported(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return v.Type().Elem().Kind() == reflect.String
+	}
+
+	return false
+}
+
+// setValue parses the text into the field. Lists are comma separated
+func setValue(v reflect.Value, text string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(text)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int, v.Kind() == reflect.Int32, v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	}
+
+	return nil
+}
+
+// envName is the environment variable of the field: BLOG + tls.cert-file
+// is BLOG_TLS_CERT_FILE
+func envName(prefix, name string) string {
+	name = strings.NewReplacer(".", "_", "-", "_").Replace(name)
+	return strings.ToUpper(prefix + "_" + name)
+}
+
+func usage(prefix string, f *field) string {
+	return fmt.Sprintf("%s (env %s)", f.usage, envName(prefix, f.name))
+}
+
+// pendingFlag keeps the flag value until the file and the environment are applied
+type pendingFlag struct {
+	field *field
+	value string
+	set   *[]*pendingFlag
+}
+
+func (p *pendingFlag) String() string {
+	if p == nil || p.field == nil {
+		return ""
+	}
+
+	return formatValue(p.field.value)
+}
+
+func (p *pendingFlag) Set(value string) error {
+	// check the value now to report it with the flag usage
+	if err := setValue(reflect.New(p.field.value.Type()).Elem(), value); err != nil {
+		return err
+	}
+	p.value = value
+	*p.set = append(*p.set, p)
+
+	return nil
+}
+
+// IsBoolFlag lets the bool fields be set with -name alone
+func (p *pendingFlag) IsBoolFlag() bool {
+	return p.field.value.Kind() == reflect.Bool
+}
+
+func formatValue(v reflect.Value) string {
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// loadFile sets the fields from the YAML or TOML file. Unknown keys are
+// errors, the keys without a value (a YAML "key:" or a section with only
+// comments) keep the field as it is
+func loadFile(path string, fields map[string]*field) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		doc := map[interface{}]interface{}{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("cannot parse %s: %v", path, err)
+		}
+		flatten(doc, "", values)
+	case ".toml":
+		doc := map[string]interface{}{}
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("cannot parse %s: %v", path, err)
+		}
+		flatten(doc, "", values)
+	default:
+		return fmt.Errorf("unknown config file format %q, use .yaml, .yml or .toml", filepath.Ext(path))
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f, ok := fields[name]
+		if !ok && !(values[name] == nil && isSection(name, fields)) {
+			return fmt.Errorf("unknown key %q in %s", name, path)
+		}
+		if values[name] == nil {
+			continue
+		}
+		if err := setValue(f.value, fileText(values[name])); err != nil {
+			return fmt.Errorf("invalid %s in %s: %v", name, path, err)
+		}
+	}
+
+	return nil
+}
+
+// isSection tells if name is the table of some fields
+func isSection(name string, fields map[string]*field) bool {
+	for fieldName := range fields {
+		if strings.HasPrefix(fieldName, name+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// flatten turns the nested tables of the file to the dotted field names
+func flatten(doc interface{}, prefix string, values map[string]interface{}) {
+	switch table := doc.(type) {
+	case map[interface{}]interface{}:
+		for key, value := range table {
+			flatten(value, prefix+fmt.Sprint(key)+".", values)
+		}
+		return
+	case map[string]interface{}:
+		for key, value := range table {
+			flatten(value, prefix+key+".", values)
+		}
+		return
+	}
+
+	values[strings.TrimSuffix(prefix, ".")] = doc
+}
+
+// fileText formats the decoded file value like the flags and variables are written
+func fileText(value interface{}) string {
+	list, ok := value.([]interface{})
+	if !ok {
+		return fmt.Sprint(value)
+	}
+
+	items := make([]string, len(list))
+	for i, item := range list {
+		items[i] = fmt.Sprint(item)
+	}
+
+	return strings.Join(items, ",")
+}
+
+// validate calls Validate of the nested structs and then of v
+func validate(v reflect.Value) error {
+	for i := 0; i < v.NumField(); i++ {
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && v.Type().Field(i).PkgPath == "" {
+			if err := validate(fv); err != nil {
+				return err
+			}
+		}
+	}
+
+	if validator, ok := v.Addr().Interface().(Validator); ok {
+		return validator.Validate()
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testTLS struct {
+	CertFile string `config:"cert-file"`
+	Enabled  bool   `config:"enabled"`
+}
+
+type testConfig struct {
+	Listen  string        `config:"listen"`
+	Timeout time.Duration `config:"timeout"`
+	Retries int           `config:"retries"`
+	Ratio   float64       `config:"ratio"`
+	Tags    []string      `config:"tags"`
+	TLS     testTLS       `config:"tls"`
+}
+
+func defaultTestConfig() *testConfig {
+	return &testConfig{
+		Listen:  "default:1",
+		Timeout: time.Second,
+		Retries: 1,
+		Tags:    []string{"default"},
+	}
+}
+
+// setenv sets the variable until the end of the test
+func setenv(t *testing.T, name, value string) {
+	t.Helper()
+	old, had := os.LookupEnv(name)
+	if err := os.Setenv(name, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if had {
+			os.Setenv(name, old)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
+
+// writeFile writes the config file removed after the test
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	file := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", "listen: file:1\ntimeout: 2s\nretries: 2\ntls:\n  cert-file: file.crt\n")
+	setenv(t, "CONFIGTEST_TIMEOUT", "3s")
+	setenv(t, "CONFIGTEST_RETRIES", "3")
+
+	cfg := defaultTestConfig()
+	if err := Load("CONFIGTEST", cfg, []string{"-config", file, "-retries=4"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &testConfig{
+		// file over the default
+		Listen: "file:1",
+		// environment over the file
+		Timeout: 3 * time.Second,
+		// flag over the environment
+		Retries: 4,
+		// default without the other sources
+		Tags: []string{"default"},
+		TLS:  testTLS{CertFile: "file.crt"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Load() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadFileFromEnvironment(t *testing.T) {
+	file := writeFile(t, "config.toml", "listen = \"toml:1\"\n[tls]\nenabled = true\n")
+	setenv(t, "CONFIGTEST_CONFIG", file)
+
+	cfg := defaultTestConfig()
+	if err := Load("CONFIGTEST", cfg, nil); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Listen != "toml:1" || !cfg.TLS.Enabled {
+		t.Errorf("Load() = %+v, want listen and tls.enabled from the TOML file", cfg)
+	}
+}
+
+func TestLoadValues(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		args []string
+		want func(cfg *testConfig)
+	}{
+		{"YAML list", "tags: [a, b]\n", nil, func(cfg *testConfig) { cfg.Tags = []string{"a", "b"} }},
+		{"YAML list of numbers", "tags:\n  - 1\n  - 2\n", nil, func(cfg *testConfig) { cfg.Tags = []string{"1", "2"} }},
+		{"flag list", "", []string{"-tags= a,,b ,"}, func(cfg *testConfig) { cfg.Tags = []string{"a", "b"} }},
+		{"empty flag list", "", []string{"-tags="}, func(cfg *testConfig) { cfg.Tags = nil }},
+		{"YAML duration", "timeout: 1m30s\n", nil, func(cfg *testConfig) { cfg.Timeout = 90 * time.Second }},
+		{"flag duration", "", []string{"-timeout=250ms"}, func(cfg *testConfig) { cfg.Timeout = 250 * time.Millisecond }},
+		{"float", "ratio: 0.5\n", nil, func(cfg *testConfig) { cfg.Ratio = 0.5 }},
+		{"bool flag alone", "", []string{"-tls.enabled"}, func(cfg *testConfig) { cfg.TLS.Enabled = true }},
+		{"key without value", "listen:\ntimeout:\n", nil, func(cfg *testConfig) {}},
+		{"section without keys", "tls:\n  # cert-file: a.crt\n", nil, func(cfg *testConfig) {}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, "config.yaml", tt.file)}, args...)
+			}
+
+			cfg := defaultTestConfig()
+			if err := Load("CONFIGTEST", cfg, args); err != nil {
+				t.Fatal(err)
+			}
+			want := defaultTestConfig()
+			tt.want(want)
+			if !reflect.DeepEqual(cfg, want) {
+				t.Errorf("Load() = %+v, want %+v", cfg, want)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     string
+		wantErr string
+	}{
+		{"unknown key", "listen: a\nlisen: b\n", "", `unknown key "lisen"`},
+		{"unknown nested key", "tls:\n  cert: a.crt\n", "", `unknown key "tls.cert"`},
+		{"unknown key without value", "lisen:\n", "", `unknown key "lisen"`},
+		{"invalid file duration", "timeout: 10\n", "", "invalid timeout"},
+		{"invalid file int", "retries: many\n", "", "invalid retries"},
+		{"invalid environment duration", "", "soon", "invalid CONFIGTEST_TIMEOUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args []string
+			if tt.file != "" {
+				args = []string{"-config", writeFile(t, "config.yaml", tt.file)}
+			}
+			if tt.env != "" {
+				setenv(t, "CONFIGTEST_TIMEOUT", tt.env)
+			}
+
+			err := Load("CONFIGTEST", defaultTestConfig(), args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() = %v, want an error with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadUnknownFormat(t *testing.T) {
+	file := writeFile(t, "config.json", "{}")
+	if err := Load("CONFIGTEST", defaultTestConfig(), []string{"-config", file}); err == nil {
+		t.Error("Load() of a .json file = nil, want an error")
+	}
+}
+
+type validatedTLS struct {
+	CertFile string `config:"cert-file"`
+}
+
+func (c *validatedTLS) Validate() error {
+	if c.CertFile == "" {
+		return errors.New("cert-file is required")
+	}
+	return nil
+}
+
+type validatedConfig struct {
+	TLS validatedTLS `config:"tls"`
+}
+
+func TestLoadValidates(t *testing.T) {
+	if err := Load("CONFIGTEST", &validatedConfig{}, nil); err == nil || err.Error() != "cert-file is required" {
+		t.Errorf("Load() = %v, want the nested Validate error", err)
+	}
+	if err := Load("CONFIGTEST", &validatedConfig{}, []string{"-tls.cert-file=a.crt"}); err != nil {
+		t.Errorf("Load() = %v, want nil", err)
+	}
+}
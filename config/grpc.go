@@ -0,0 +1,172 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/auth"
+	"github.com/KestutisKazlauskas/grpc-go/certs"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Server is the configuration shared by the servers
+type Server struct {
+	Listen          string        `config:"listen" usage:"Address the server listens on"`
+	ShutdownTimeout time.Duration `config:"shutdown-timeout" usage:"How long the pending RPCs can take on shutdown before the connections are closed"`
+	TLS             ServerTLS     `config:"tls"`
+	Auth            auth.Config   `config:"auth"`
+}
+
+// DefaultServer returns the defaults of the servers
+func DefaultServer() Server {
+	return Server{
+		Listen:          "0.0.0.0:50051",
+		ShutdownTimeout: 30 * time.Second,
+		TLS:             ServerTLS{ReloadInterval: 10 * time.Second},
+	}
+}
+
+// ServerTLS is the certificate of a server and how the client
+// certificates are checked for mutual TLS
+type ServerTLS struct {
+	Enabled      bool   `config:"enabled" usage:"Serve with TLS"`
+	CertFile     string `config:"cert-file" usage:"Server certificate PEM file"`
+	KeyFile      string `config:"key-file" usage:"Server private key PEM file"`
+	ClientCAFile string `config:"client-ca-file" usage:"CA bundle PEM file the client certificates are verified with"`
+	ClientAuth   string `config:"client-auth" usage:"Client certificate check: none, request, require, verify-if-given or require-and-verify. require-and-verify if empty and client-ca-file is set"`
+	// The files are also reloaded on SIGHUP
+	ReloadInterval time.Duration `config:"reload-interval" usage:"How often the certificate files are checked for changes, never if 0"`
+}
+
+// clientAuthTypes are the client-auth settings
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify-if-given":    tls.VerifyClientCertIfGiven,
+	"require-and-verify": tls.RequireAndVerifyClientCert,
+}
+
+// Client is the configuration shared by the clients
+type Client struct {
+	Target string    `config:"target" usage:"Address of the server"`
+	TLS    ClientTLS `config:"tls"`
+	// Token is better kept in the environment than in a flag
+	Token string `config:"token" usage:"Bearer token, a JWT or an API key, sent with every RPC"`
+}
+
+// ClientTLS tells how a client checks the server certificate and the
+// client certificate sent for mutual TLS
+type ClientTLS struct {
+	Enabled    bool   `config:"enabled" usage:"Connect with TLS"`
+	CAFile     string `config:"ca-file" usage:"CA certificate PEM file, the system roots if empty"`
+	ServerName string `config:"server-name" usage:"Name checked in the server certificate, the target host if empty"`
+	CertFile   string `config:"cert-file" usage:"Client certificate PEM file for mutual TLS"`
+	KeyFile    string `config:"key-file" usage:"Client private key PEM file for mutual TLS"`
+}
+
+// Validate checks the listen address and the shutdown timeout
+func (s *Server) Validate() error {
+	if _, _, err := net.SplitHostPort(s.Listen); err != nil {
+		return fmt.Errorf("invalid listen address %q: %v", s.Listen, err)
+	}
+	if s.ShutdownTimeout < 0 {
+		return errors.New("shutdown-timeout can not be negative")
+	}
+
+	return nil
+}
+
+// Validate checks the certificate files are set when TLS is enabled
+// and the client-auth setting
+func (t *ServerTLS) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+	if t.CertFile == "" || t.KeyFile == "" {
+		return errors.New("tls.cert-file and tls.key-file are required with TLS")
+	}
+	if t.ClientAuth != "" {
+		if _, ok := clientAuthTypes[t.ClientAuth]; !ok {
+			return fmt.Errorf("unknown tls.client-auth %q, use none, request, require, verify-if-given or require-and-verify", t.ClientAuth)
+		}
+	}
+	if t.ReloadInterval < 0 {
+		return errors.New("tls.reload-interval can not be negative")
+	}
+	if t.ClientCAFile == "" && (t.ClientAuth == "verify-if-given" || t.ClientAuth == "require-and-verify") {
+		return fmt.Errorf("tls.client-ca-file is required with tls.client-auth %s", t.ClientAuth)
+	}
+
+	return nil
+}
+
+// ClientAuthType is the client certificate check of the settings
+func (t *ServerTLS) ClientAuthType() tls.ClientAuthType {
+	if t.ClientAuth == "" && t.ClientCAFile != "" {
+		return tls.RequireAndVerifyClientCert
+	}
+
+	return clientAuthTypes[t.ClientAuth]
+}
+
+// Validate checks the target is set
+func (c *Client) Validate() error {
+	if c.Target == "" {
+		return errors.New("target is required")
+	}
+
+	return nil
+}
+
+// Validate checks the client certificate and key are set together
+func (t *ClientTLS) Validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return errors.New("tls.cert-file and tls.key-file must be set together")
+	}
+
+	return nil
+}
+
+// DialOptions returns the transport credentials option of the client
+func (t *ClientTLS) DialOptions() ([]grpc.DialOption, error) {
+	if !t.Enabled {
+		return []grpc.DialOption{grpc.WithInsecure()}, nil
+	}
+
+	cfg := &tls.Config{ServerName: t.ServerName}
+	if t.CAFile != "" {
+		var err error
+		cfg.RootCAs, err = certs.LoadCertPool(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the CA certificate: %v", err)
+		}
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(cfg))}, nil
+}
+
+// Dial connects to the target with the TLS settings and the token
+func (c *Client) Dial(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	tlsOpts, err := c.TLS.DialOptions()
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		tlsOpts = append(tlsOpts, grpc.WithPerRPCCredentials(auth.NewTokenCredentials(c.Token, c.TLS.Enabled)))
+	}
+
+	return grpc.Dial(c.Target, append(tlsOpts, opts...)...)
+}
@@ -3,8 +3,12 @@ module github.com/KestutisKazlauskas/grpc-go
 go 1.14
 
 require (
-	github.com/golang/protobuf v1.4.0-rc.4.0.20200313231945-b860323f09d0
-	go.mongodb.org/mongo-driver v1.3.3 // indirect
+	github.com/BurntSushi/toml v0.4.1
+	github.com/golang/protobuf v1.4.3
+	go.mongodb.org/mongo-driver v1.5.4
+	golang.org/x/net v0.0.0-20200202094626-16171245cfb2
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
 	google.golang.org/grpc v1.29.1
-	google.golang.org/protobuf v1.20.1-0.20200309200217-e05f789c0967
+	google.golang.org/protobuf v1.25.0
+	gopkg.in/yaml.v2 v2.4.0
 )
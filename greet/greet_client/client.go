@@ -5,12 +5,12 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"time"
 
+	"github.com/KestutisKazlauskas/grpc-go/config"
 	"github.com/KestutisKazlauskas/grpc-go/greet/greetpb"
-	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 )
 
@@ -18,15 +18,16 @@ func main() {
 
 	fmt.Println("Client started")
 
-	//connection without ssl
-	creds, sslErr := credentials.NewClientTLSFromFile("ssl/ca.crt", "")
-	if sslErr != nil {
-		log.Fatalf("Error geting crediantilsa from Authorety trust certificate Certificate %v", sslErr)
+	// set GREET_CLIENT_TLS_ENABLED=false if want to use without tsl
+	cfg := &config.Client{
+		Target: "localhost:50051",
+		TLS:    config.ClientTLS{Enabled: true, CAFile: "ssl/ca.crt"},
+	}
+	if err := config.Load("GREET_CLIENT", cfg, os.Args[1:]); err != nil {
+		log.Fatalf("Config error %v", err)
 	}
 
-	opts := grpc.WithTransportCredentials(creds)
-	// opts := grpc.WithInsecure() if want to use without tsl
-	conn, err := grpc.Dial("localhost:50051", opts)
+	conn, err := cfg.Dial()
 	if err != nil {
 		log.Fatalf("Error on initiatning connection %v", err)
 	}
@@ -0,0 +1,192 @@
+// Package grpcserver builds the grpc servers of the services with the
+// shared TLS settings, request validation, reflection and health checking
+package grpcserver
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/KestutisKazlauskas/grpc-go/auth"
+	"github.com/KestutisKazlauskas/grpc-go/authz"
+	"github.com/KestutisKazlauskas/grpc-go/certs"
+	"github.com/KestutisKazlauskas/grpc-go/config"
+	"github.com/KestutisKazlauskas/grpc-go/validate"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// Server is a grpc server with the health service
+type Server struct {
+	*grpc.Server
+	Health *health.Server
+	// Policy authorizes the RPCs, nil without a policy file. The services
+	// add their owners to it
+	Policy *authz.Policy
+	// stopCerts stops the certificate reloading
+	stopCerts context.CancelFunc
+	// ctx is done when the server starts stopping
+	ctx      context.Context
+	stopping context.CancelFunc
+}
+
+// New returns a server with the TLS of the config and the authentication
+// and validation interceptors. More interceptors can be chained with the
+// options. The certificates are reloaded until the server is stopped
+func New(cfg config.Server, opts ...grpc.ServerOption) (*Server, error) {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(validate.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(validate.StreamServerInterceptor()),
+	}, opts...)
+
+	// The callers are authenticated and authorized before their requests
+	// are validated
+	var policy *authz.Policy
+	if cfg.Auth.PolicyFile != "" {
+		var err error
+		policy, err = authz.Load(cfg.Auth.PolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the policy: %v", err)
+		}
+		opts = append([]grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(authz.UnaryServerInterceptor(policy)),
+			grpc.ChainStreamInterceptor(authz.StreamServerInterceptor(policy)),
+		}, opts...)
+	}
+	if cfg.Auth.Enabled {
+		authenticator, err := auth.New(cfg.Auth)
+		if err != nil {
+			return nil, err
+		}
+		// The policy can open methods to everyone
+		if policy != nil {
+			authenticator.AllowAnonymous(policy.AllowsAnonymous)
+		}
+		opts = append([]grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(authenticator)),
+			grpc.ChainStreamInterceptor(auth.StreamServerInterceptor(authenticator)),
+		}, opts...)
+	}
+
+	stopCerts := func() {}
+	if cfg.TLS.Enabled {
+		manager, err := certs.NewManager(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append([]grpc.ServerOption{grpc.Creds(credentials.NewTLS(manager.ServerConfig(cfg.TLS.ClientAuthType())))}, opts...)
+
+		var ctx context.Context
+		ctx, stopCerts = context.WithCancel(context.Background())
+		go manager.Watch(ctx, cfg.TLS.ReloadInterval)
+	}
+
+	ctx, stopping := context.WithCancel(context.Background())
+	s := &Server{
+		Server:    grpc.NewServer(opts...),
+		Health:    health.NewServer(),
+		Policy:    policy,
+		stopCerts: stopCerts,
+		ctx:       ctx,
+		stopping:  stopping,
+	}
+	healthpb.RegisterHealthServer(s.Server, s.Health)
+	// Register reflection service on gRPC server.
+	//Install  evans and using cli for reflection
+	reflection.Register(s.Server)
+
+	return s, nil
+}
+
+// Serve marks the registered services without a health status as serving
+// and serves the listener
+func (s *Server) Serve(listen net.Listener) error {
+	for name := range s.GetServiceInfo() {
+		req := &healthpb.HealthCheckRequest{Service: name}
+		if _, err := s.Health.Check(context.Background(), req); status.Code(err) == codes.NotFound {
+			s.Health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+		}
+	}
+	// The empty name is the health of the whole server
+	s.Health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	return s.Server.Serve(listen)
+}
+
+// Context is done when the server starts stopping. The streams which run
+// until the client leaves end with it so they do not hold up GracefulStop
+func (s *Server) Context() context.Context {
+	return s.ctx
+}
+
+// Stop reports every service as NOT_SERVING and stops the server
+func (s *Server) Stop() {
+	s.stopping()
+	s.stopCerts()
+	s.Health.Shutdown()
+	s.Server.Stop()
+}
+
+// GracefulStop reports every service as NOT_SERVING, ends the streams
+// watching Context and stops the server after the pending RPCs finish
+func (s *Server) GracefulStop() {
+	s.stopping()
+	s.stopCerts()
+	s.Health.Shutdown()
+	s.Server.GracefulStop()
+}
+
+// Shutdown reports every service as NOT_SERVING and waits up to drain for
+// the pending RPCs to finish. The connections still open after drain are
+// closed
+func (s *Server) Shutdown(drain time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(drain)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		log.Printf("Pending RPCs did not finish in %v, closing the connections", drain)
+		s.Server.Stop()
+		<-done
+	}
+}
+
+// ServeUntilSignal serves the listener until SIGINT or SIGTERM and then
+// shuts the server down, see Shutdown
+func (s *Server) ServeUntilSignal(listen net.Listener, drain time.Duration) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.Serve(listen)
+	}()
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(ch)
+
+	select {
+	case err := <-errc:
+		return err
+	case sig := <-ch:
+		log.Printf("Received %v, stopping the server", sig)
+	}
+	s.Shutdown(drain)
+
+	return <-errc
+}
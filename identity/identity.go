@@ -0,0 +1,64 @@
+// Package identity reads the identity of the client from the verified TLS
+// certificate of the connection
+package identity
+
+import (
+	"crypto/x509"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Identity is the subject and the SANs of a client certificate
+type Identity struct {
+	// Subject is the common name, the whole subject if it has none
+	Subject  string
+	DNSNames []string
+	URIs     []string
+	Emails   []string
+}
+
+// FromCertificate returns the identity of the certificate
+func FromCertificate(cert *x509.Certificate) *Identity {
+	id := &Identity{
+		Subject:  cert.Subject.String(),
+		DNSNames: cert.DNSNames,
+		Emails:   cert.EmailAddresses,
+	}
+	if cert.Subject.CommonName != "" {
+		id.Subject = cert.Subject.CommonName
+	}
+	for _, uri := range cert.URIs {
+		id.URIs = append(id.URIs, uri.String())
+	}
+
+	return id
+}
+
+// FromContext returns the identity of the client of the RPC. ok is false
+// when the client did not send a verified certificate
+func FromContext(ctx context.Context) (id *Identity, ok bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	return FromCertificate(info.State.VerifiedChains[0][0]), true
+}
+
+// Name is the first URI SAN, DNS SAN or email SAN and the subject
+// common name when the certificate has no SANs
+func (id *Identity) Name() string {
+	for _, names := range [][]string{id.URIs, id.DNSNames, id.Emails} {
+		if len(names) > 0 {
+			return names[0]
+		}
+	}
+
+	return id.Subject
+}
@@ -0,0 +1,91 @@
+package rpcerror
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Details are the known details of a status error received by a client
+type Details struct {
+	Code       codes.Code
+	Message    string
+	Info       *errdetails.ErrorInfo
+	Resource   *errdetails.ResourceInfo
+	BadRequest *errdetails.BadRequest
+	Retry      *errdetails.RetryInfo
+}
+
+// FromError decodes the status error. ok is false for errors
+// which are not grpc status errors
+func FromError(err error) (details *Details, ok bool) {
+	st, ok := status.FromError(err)
+	details = &Details{
+		Code:    st.Code(),
+		Message: st.Message(),
+	}
+
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			details.Info = d
+		case *errdetails.ResourceInfo:
+			details.Resource = d
+		case *errdetails.BadRequest:
+			details.BadRequest = d
+		case *errdetails.RetryInfo:
+			details.Retry = d
+		}
+	}
+
+	return details, ok
+}
+
+// Reason returns the ErrorInfo reason, empty if there is no ErrorInfo
+func (d *Details) Reason() string {
+	return d.Info.GetReason()
+}
+
+// RetryDelay returns the RetryInfo delay. ok is false if the server
+// did not say the request can be retried
+func (d *Details) RetryDelay() (delay time.Duration, ok bool) {
+	if d.Retry == nil {
+		return 0, false
+	}
+
+	return d.Retry.GetRetryDelay().AsDuration(), true
+}
+
+// String describes the error with its details on one line
+func (d *Details) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v: %s", d.Code, d.Message)
+	if d.Info != nil {
+		fmt.Fprintf(&b, " (reason %s", d.Info.GetReason())
+		keys := make([]string, 0, len(d.Info.GetMetadata()))
+		for key := range d.Info.GetMetadata() {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, ", %s=%s", key, d.Info.GetMetadata()[key])
+		}
+		b.WriteString(")")
+	}
+	if d.Resource != nil {
+		fmt.Fprintf(&b, " [%s %q]", d.Resource.GetResourceType(), d.Resource.GetResourceName())
+	}
+	for _, violation := range d.BadRequest.GetFieldViolations() {
+		fmt.Fprintf(&b, "; %s %s", violation.GetField(), violation.GetDescription())
+	}
+	if delay, ok := d.RetryDelay(); ok {
+		fmt.Fprintf(&b, "; retry after %v", delay)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,147 @@
+// Package rpcerror builds grpc status errors with google.rpc error details
+// (ErrorInfo, ResourceInfo, BadRequest) and decodes them on the client side
+package rpcerror
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is the ErrorInfo domain of the errors returned by the services
+const Domain = "github.com/KestutisKazlauskas/grpc-go"
+
+// Error is a status error with its details
+type Error struct {
+	Code    codes.Code
+	Message string
+	// Reason is the UPPER_SNAKE_CASE ErrorInfo reason, no ErrorInfo if empty
+	Reason   string
+	Metadata map[string]string
+	// ResourceType and ResourceName are sent as ResourceInfo if the type is set
+	ResourceType string
+	ResourceName string
+	Violations   []*errdetails.BadRequest_FieldViolation
+	// RetryDelay is sent as RetryInfo if set, the request can be retried after it
+	RetryDelay time.Duration
+}
+
+// New returns an error with an ErrorInfo reason
+func New(code codes.Code, reason, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+		Reason:  reason,
+	}
+}
+
+// WithMetadata adds the key to the ErrorInfo metadata
+func (e *Error) WithMetadata(key, value string) *Error {
+	if e.Metadata == nil {
+		e.Metadata = map[string]string{}
+	}
+	e.Metadata[key] = value
+
+	return e
+}
+
+// WithResource sets the resource the error is about
+func (e *Error) WithResource(resourceType, name string) *Error {
+	e.ResourceType = resourceType
+	e.ResourceName = name
+
+	return e
+}
+
+// WithViolation adds a BadRequest field violation
+func (e *Error) WithViolation(field, description string) *Error {
+	e.Violations = append(e.Violations, &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	})
+
+	return e
+}
+
+// WithRetryDelay tells the client to retry the request after the delay
+func (e *Error) WithRetryDelay(delay time.Duration) *Error {
+	e.RetryDelay = delay
+
+	return e
+}
+
+// Status returns the grpc status with the details
+func (e *Error) Status() *status.Status {
+	st := status.New(e.Code, e.Message)
+
+	var details []proto.Message
+	if e.Reason != "" {
+		details = append(details, &errdetails.ErrorInfo{
+			Reason:   e.Reason,
+			Domain:   Domain,
+			Metadata: e.Metadata,
+		})
+	}
+	if e.ResourceType != "" {
+		details = append(details, &errdetails.ResourceInfo{
+			ResourceType: e.ResourceType,
+			ResourceName: e.ResourceName,
+		})
+	}
+	if len(e.Violations) > 0 {
+		details = append(details, &errdetails.BadRequest{FieldViolations: e.Violations})
+	}
+	if e.RetryDelay > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryDelay)})
+	}
+	if len(details) == 0 {
+		return st
+	}
+
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+
+	return detailed
+}
+
+// Err returns the grpc status error
+func (e *Error) Err() error {
+	return e.Status().Err()
+}
+
+// Rule maps a storage error to the error returned to the clients
+type Rule struct {
+	// Target is matched with errors.Is
+	Target       error
+	Code         codes.Code
+	Reason       string
+	Message      string
+	ResourceType string
+	RetryDelay   time.Duration
+}
+
+// Map returns the error of the first rule matching err. Errors matching no
+// rule are Internal with the fallback message. The text of err is never
+// sent so storage details do not leak to the clients
+func Map(err error, rules []Rule, resourceName string, fallback string) *Error {
+	for _, rule := range rules {
+		if !errors.Is(err, rule.Target) {
+			continue
+		}
+
+		e := New(rule.Code, rule.Reason, rule.Message).WithRetryDelay(rule.RetryDelay)
+		if rule.ResourceType != "" {
+			e.WithResource(rule.ResourceType, resourceName)
+		}
+		return e
+	}
+
+	return New(codes.Internal, "INTERNAL", fallback)
+}
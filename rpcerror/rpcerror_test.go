@@ -0,0 +1,121 @@
+package rpcerror
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		want string
+	}{
+		{
+			"no details",
+			&Error{Code: codes.NotFound, Message: "Cannot find blog"},
+			"NotFound: Cannot find blog",
+		},
+		{
+			"reason with sorted metadata",
+			New(codes.Aborted, "REVISION_MISMATCH", "Blog was changed").WithMetadata("revision", "3").WithMetadata("expected", "2"),
+			"Aborted: Blog was changed (reason REVISION_MISMATCH, expected=2, revision=3)",
+		},
+		{
+			"resource",
+			New(codes.NotFound, "BLOG_NOT_FOUND", "Cannot find blog").WithResource("blog", "1"),
+			`NotFound: Cannot find blog (reason BLOG_NOT_FOUND) [blog "1"]`,
+		},
+		{
+			"violations",
+			New(codes.InvalidArgument, "SUM_OVERFLOW", "Sum does not fit").WithViolation("x", "too big").WithViolation("y", "too big"),
+			"InvalidArgument: Sum does not fit (reason SUM_OVERFLOW); x too big; y too big",
+		},
+		{
+			"retry delay",
+			New(codes.Unavailable, "STORAGE_UNAVAILABLE", "Try again").WithRetryDelay(2 * time.Second),
+			"Unavailable: Try again (reason STORAGE_UNAVAILABLE); retry after 2s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details, ok := FromError(tt.err.Err())
+			if !ok {
+				t.Fatalf("FromError() ok = false, want true")
+			}
+			if got := details.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if details.Code != tt.err.Code || details.Reason() != tt.err.Reason {
+				t.Errorf("FromError() = %v %q, want %v %q", details.Code, details.Reason(), tt.err.Code, tt.err.Reason)
+			}
+			if details.Info != nil && details.Info.GetDomain() != Domain {
+				t.Errorf("domain = %q, want %q", details.Info.GetDomain(), Domain)
+			}
+			delay, ok := details.RetryDelay()
+			if delay != tt.err.RetryDelay || ok != (tt.err.RetryDelay > 0) {
+				t.Errorf("RetryDelay() = %v %v, want %v", delay, ok, tt.err.RetryDelay)
+			}
+		})
+	}
+}
+
+func TestFromErrorNotStatus(t *testing.T) {
+	details, ok := FromError(errors.New("plain"))
+	if ok || details.Code != codes.Unknown || details.Reason() != "" {
+		t.Errorf("FromError() = %v %v, want Unknown and ok false", details, ok)
+	}
+}
+
+var (
+	errTestNotFound = errors.New("not found")
+	errTestDown     = errors.New("down")
+)
+
+var testRules = []Rule{
+	{Target: errTestNotFound, Code: codes.NotFound, Reason: "BLOG_NOT_FOUND", Message: "Cannot find blog", ResourceType: "blog"},
+	{Target: errTestDown, Code: codes.Unavailable, Reason: "STORAGE_UNAVAILABLE", Message: "Storage is unavailable", RetryDelay: time.Second},
+}
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			"wrapped",
+			fmt.Errorf("read 1: %w", errTestNotFound),
+			`NotFound: Cannot find blog (reason BLOG_NOT_FOUND) [blog "1"]`,
+		},
+		{
+			"without resource type",
+			errTestDown,
+			"Unavailable: Storage is unavailable (reason STORAGE_UNAVAILABLE); retry after 1s",
+		},
+		{
+			"no rule",
+			errors.New("connection to db.internal:27017 refused"),
+			"Internal: Cannot read blog (reason INTERNAL)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details, _ := FromError(Map(tt.err, testRules, "1", "Cannot read blog").Err())
+			got := details.String()
+			if got != tt.want {
+				t.Errorf("Map() = %q, want %q", got, tt.want)
+			}
+			if strings.Contains(got, tt.err.Error()) {
+				t.Errorf("Map() = %q, leaks the error text", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package validate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/blog.BlogService/ReadBlog"}
+
+	tests := []struct {
+		name        string
+		req         proto.Message
+		wantHandled bool
+	}{
+		{"valid", &blogpb.ReadBlogRequest{BlogId: "blog"}, true},
+		{"invalid", &blogpb.ReadBlogRequest{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled := false
+			_, err := interceptor(context.Background(), tt.req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				handled = true
+				return nil, nil
+			})
+			if handled != tt.wantHandled {
+				t.Errorf("handler called = %v, want %v", handled, tt.wantHandled)
+			}
+			if tt.wantHandled {
+				if err != nil {
+					t.Errorf("interceptor() = %v, want nil", err)
+				}
+				return
+			}
+			wantBadRequest(t, err, tt.req)
+		})
+	}
+}
+
+// testStream receives the message once
+type testStream struct {
+	grpc.ServerStream
+	msg proto.Message
+}
+
+func (s *testStream) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), s.msg)
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/blog.BlogService/WatchBlogs", IsServerStream: true}
+
+	tests := []struct {
+		name    string
+		msg     proto.Message
+		wantErr bool
+	}{
+		{"valid", &blogpb.GetBlogRevisionRequest{BlogId: "blog", Revision: 1}, false},
+		{"invalid", &blogpb.GetBlogRevisionRequest{BlogId: "blog"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recvErr error
+			err := interceptor(nil, &testStream{msg: tt.msg}, info, func(srv interface{}, stream grpc.ServerStream) error {
+				recvErr = stream.RecvMsg(&blogpb.GetBlogRevisionRequest{})
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("interceptor() = %v, want the Recv error only", err)
+			}
+			if !tt.wantErr {
+				if recvErr != nil {
+					t.Errorf("RecvMsg() = %v, want nil", recvErr)
+				}
+				return
+			}
+			wantBadRequest(t, recvErr, tt.msg)
+		})
+	}
+}
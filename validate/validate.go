@@ -0,0 +1,212 @@
+// Package validate checks request messages against the validate.field rules
+// of their proto fields (see validatepb/validate.proto)
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+	"github.com/KestutisKazlauskas/grpc-go/validate/validatepb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// patterns caches the compiled rule patterns
+var patterns sync.Map
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+
+	return re, nil
+}
+
+// rules returns the validate.field option of the field, nil if not set
+func rules(field protoreflect.FieldDescriptor) *validatepb.FieldRules {
+	opts := field.Options()
+	if opts == nil || !proto.HasExtension(opts, validatepb.E_Field) {
+		return nil
+	}
+
+	return proto.GetExtension(opts, validatepb.E_Field).(*validatepb.FieldRules)
+}
+
+// Violations returns the broken rules of the message and its nested messages
+func Violations(msg proto.Message) []*errdetails.BadRequest_FieldViolation {
+	if msg == nil {
+		return nil
+	}
+
+	return checkMessage(msg.ProtoReflect(), "")
+}
+
+// Check returns an InvalidArgument status error with the violations in
+// a BadRequest detail if the message breaks any rule
+func Check(msg proto.Message) error {
+	violations := Violations(msg)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	e := rpcerror.New(
+		codes.InvalidArgument,
+		"INVALID_REQUEST",
+		fmt.Sprintf("Invalid %s: %s %s", msg.ProtoReflect().Descriptor().Name(), violations[0].GetField(), violations[0].GetDescription()),
+	)
+	e.Violations = violations
+
+	return e.Err()
+}
+
+func checkMessage(msg protoreflect.Message, prefix string) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		path := prefix + string(field.Name())
+
+		if r := rules(field); r != nil {
+			violations = append(violations, checkField(msg, field, r, path)...)
+		}
+
+		// nested messages have their own rules
+		if field.Kind() != protoreflect.MessageKind || field.IsMap() || !msg.Has(field) {
+			continue
+		}
+		if field.IsList() {
+			list := msg.Get(field).List()
+			for j := 0; j < list.Len(); j++ {
+				violations = append(violations, checkMessage(list.Get(j).Message(), fmt.Sprintf("%s[%d].", path, j))...)
+			}
+			continue
+		}
+		violations = append(violations, checkMessage(msg.Get(field).Message(), path+".")...)
+	}
+
+	return violations
+}
+
+func violation(path, format string, args ...interface{}) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       path,
+		Description: fmt.Sprintf(format, args...),
+	}
+}
+
+func checkField(msg protoreflect.Message, field protoreflect.FieldDescriptor, r *validatepb.FieldRules, path string) []*errdetails.BadRequest_FieldViolation {
+	if !msg.Has(field) {
+		if r.GetRequired() {
+			return []*errdetails.BadRequest_FieldViolation{violation(path, "is required")}
+		}
+		if r.GetRange() != nil && isInt(field) {
+			// zero must be in the range too
+			return checkInt(0, r, path)
+		}
+		return nil
+	}
+
+	value := msg.Get(field)
+	if field.IsList() {
+		list := value.List()
+		var violations []*errdetails.BadRequest_FieldViolation
+		if r.GetMaxItems() > 0 && uint32(list.Len()) > r.GetMaxItems() {
+			violations = append(violations, violation(path, "has %d items, max is %d", list.Len(), r.GetMaxItems()))
+		}
+		for i := 0; i < list.Len(); i++ {
+			violations = append(violations, checkValue(field, list.Get(i), r, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return violations
+	}
+
+	return checkValue(field, value, r, path)
+}
+
+func isInt(field protoreflect.FieldDescriptor) bool {
+	switch field.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return true
+	}
+
+	return false
+}
+
+func checkValue(field protoreflect.FieldDescriptor, value protoreflect.Value, r *validatepb.FieldRules, path string) []*errdetails.BadRequest_FieldViolation {
+	switch {
+	case field.Kind() == protoreflect.StringKind:
+		return checkString(value.String(), r, path)
+	case isInt(field):
+		return checkInt(value.Int(), r, path)
+	case field.Kind() == protoreflect.MessageKind:
+		return checkRequiredFields(value.Message(), r, path)
+	}
+
+	return nil
+}
+
+func checkString(value string, r *validatepb.FieldRules, path string) []*errdetails.BadRequest_FieldViolation {
+	if value == "" {
+		if r.GetRequired() {
+			return []*errdetails.BadRequest_FieldViolation{violation(path, "is required")}
+		}
+		return nil
+	}
+
+	length := uint32(utf8.RuneCountInString(value))
+	if r.GetMinLen() > 0 && length < r.GetMinLen() {
+		return []*errdetails.BadRequest_FieldViolation{violation(path, "has %d characters, min is %d", length, r.GetMinLen())}
+	}
+	if r.GetMaxLen() > 0 && length > r.GetMaxLen() {
+		return []*errdetails.BadRequest_FieldViolation{violation(path, "has %d characters, max is %d", length, r.GetMaxLen())}
+	}
+
+	if r.GetPattern() != "" {
+		re, err := compile(r.GetPattern())
+		if err != nil {
+			return []*errdetails.BadRequest_FieldViolation{violation(path, "has an invalid rule pattern %v", err)}
+		}
+		if !re.MatchString(value) {
+			return []*errdetails.BadRequest_FieldViolation{violation(path, "does not match %s", r.GetPattern())}
+		}
+	}
+
+	return nil
+}
+
+func checkInt(value int64, r *validatepb.FieldRules, path string) []*errdetails.BadRequest_FieldViolation {
+	if rng := r.GetRange(); rng != nil && (value < rng.GetMin() || value > rng.GetMax()) {
+		return []*errdetails.BadRequest_FieldViolation{violation(path, "is %d, must be from %d to %d", value, rng.GetMin(), rng.GetMax())}
+	}
+
+	return nil
+}
+
+func checkRequiredFields(msg protoreflect.Message, r *validatepb.FieldRules, path string) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	fields := msg.Descriptor().Fields()
+	for _, name := range r.GetRequiredFields() {
+		field := fields.ByName(protoreflect.Name(name))
+		if field == nil {
+			violations = append(violations, violation(path, "has no field %q required by the rules", name))
+			continue
+		}
+		if !msg.Has(field) {
+			violations = append(violations, violation(path+"."+name, "is required"))
+		}
+	}
+
+	return violations
+}
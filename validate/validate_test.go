@@ -0,0 +1,150 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KestutisKazlauskas/grpc-go/blog/blogpb"
+	"github.com/KestutisKazlauskas/grpc-go/rpcerror"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// fields returns the violations of the message as "field: description"
+func fields(msg proto.Message) []string {
+	var got []string
+	for _, v := range Violations(msg) {
+		got = append(got, v.GetField()+": "+v.GetDescription())
+	}
+
+	return got
+}
+
+func validBlogs(n int) []*blogpb.Blog {
+	blogs := make([]*blogpb.Blog, n)
+	for i := range blogs {
+		blogs[i] = &blogpb.Blog{AuthorId: "author", Title: "title"}
+	}
+
+	return blogs
+}
+
+func TestViolations(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want []string
+	}{
+		{
+			"valid",
+			&blogpb.GetBlogRevisionRequest{BlogId: "blog", Revision: 1},
+			nil,
+		},
+		{
+			"required string",
+			&blogpb.ReadBlogRequest{},
+			[]string{"blog_id: is required"},
+		},
+		{
+			"unset int checked as zero",
+			&blogpb.GetBlogRevisionRequest{BlogId: "blog"},
+			[]string{"revision: is 0, must be from 1 to 9223372036854775807"},
+		},
+		{
+			"int out of range",
+			&blogpb.GetBlogRevisionRequest{BlogId: "blog", Revision: -2},
+			[]string{"revision: is -2, must be from 1 to 9223372036854775807"},
+		},
+		{
+			"required message",
+			&blogpb.CreateBlogRequest{},
+			[]string{"blog: is required"},
+		},
+		{
+			"required fields of a message",
+			&blogpb.CreateBlogRequest{Blog: &blogpb.Blog{Content: "content"}},
+			[]string{"blog.author_id: is required", "blog.title: is required"},
+		},
+		{
+			"nested field",
+			&blogpb.CreateBlogRequest{Blog: &blogpb.Blog{AuthorId: "author", Title: strings.Repeat("t", 201)}},
+			[]string{"blog.title: has 201 characters, max is 200"},
+		},
+		{
+			"pattern",
+			&blogpb.CreateAuthorRequest{Author: &blogpb.Author{DisplayName: "name", Email: "not an email"}},
+			[]string{`author.email: does not match ^[^@\s]+@[^@\s]+$`},
+		},
+		{
+			"max items",
+			&blogpb.BatchCreateBlogsRequest{Blogs: validBlogs(1001)},
+			[]string{"blogs: has 1001 items, max is 1000"},
+		},
+		{
+			"max items of a list at the limit",
+			&blogpb.BatchCreateBlogsRequest{Blogs: validBlogs(1000)},
+			nil,
+		},
+		{
+			"required fields of every item",
+			&blogpb.BatchCreateBlogsRequest{Blogs: []*blogpb.Blog{
+				{AuthorId: "author", Title: "title"},
+				{AuthorId: "author"},
+				{Content: "content"},
+			}},
+			[]string{"blogs[1].title: is required", "blogs[2].author_id: is required", "blogs[2].title: is required"},
+		},
+		{
+			"fields of every item",
+			&blogpb.BatchCreateBlogsRequest{Blogs: []*blogpb.Blog{
+				{AuthorId: "author", Title: "title", Tags: []string{"go", strings.Repeat("t", 51)}},
+			}},
+			[]string{"blogs[0].tags[1]: has 51 characters, max is 50"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fields(tt.msg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Violations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// wantBadRequest checks the error has the InvalidArgument status with the
+// violations of the message in its BadRequest details
+func wantBadRequest(t *testing.T, err error, msg proto.Message) {
+	t.Helper()
+	details, ok := rpcerror.FromError(err)
+	if !ok {
+		t.Fatalf("error = %v, want a status", err)
+	}
+	if details.Code != codes.InvalidArgument || details.Reason() != "INVALID_REQUEST" {
+		t.Errorf("error = %v %q, want InvalidArgument INVALID_REQUEST", details.Code, details.Reason())
+	}
+
+	var got []string
+	for _, v := range details.BadRequest.GetFieldViolations() {
+		got = append(got, v.GetField()+": "+v.GetDescription())
+	}
+	if want := fields(msg); !reflect.DeepEqual(got, want) {
+		t.Errorf("field violations = %q, want %q", got, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check(&blogpb.ReadBlogRequest{BlogId: "blog"}); err != nil {
+		t.Errorf("Check() = %v, want nil", err)
+	}
+
+	msg := &blogpb.BatchCreateBlogsRequest{Blogs: []*blogpb.Blog{{AuthorId: "author"}, {Title: "title"}}}
+	err := Check(msg)
+	wantBadRequest(t, err, msg)
+	details, _ := rpcerror.FromError(err)
+	if want := "Invalid BatchCreateBlogsRequest: blogs[0].title is required"; details.Message != want {
+		t.Errorf("message = %q, want %q", details.Message, want)
+	}
+}